@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// minioClientAdapter оборачивает *minio.Client, добавляя DeleteBucketPolicy,
+// которого нет в SDK напрямую: bucket policy удаляется установкой пустой
+// политики (см. SetBucketPolicy в документации MinIO).
+type minioClientAdapter struct {
+	*minio.Client
+}
+
+func (a *minioClientAdapter) DeleteBucketPolicy(ctx context.Context, bucketName string) error {
+	return a.Client.SetBucketPolicy(ctx, bucketName, "")
+}
+
+// Шаблоны bucket policy, повторяющие пресеты анонимного доступа, принятые
+// в инструментах MinIO (ср. `mc anonymous set`).
+const (
+	PolicyTemplateNone     = "none"
+	PolicyTemplateDownload = "download"
+	PolicyTemplateUpload   = "upload"
+	PolicyTemplatePublic   = "public"
+)
+
+// runPolicyCommand обрабатывает подкоманды "policy get|apply|check".
+func runPolicyCommand(clients map[string]MinioClientInterface, args []string, cfg *Config, template, policyFilePath string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("policy subcommand required: get, apply, or check")
+	}
+
+	switch args[0] {
+	case "get":
+		return policyGet(clients, cfg)
+	case "apply":
+		return policyApply(clients, cfg, template, policyFilePath)
+	case "check":
+		return policyCheck(clients, cfg, template, policyFilePath)
+	default:
+		return fmt.Errorf("unknown policy subcommand: %s", args[0])
+	}
+}
+
+func policyGet(clients map[string]MinioClientInterface, cfg *Config) error {
+	targetsByName := make(map[string]TargetConfig)
+	for _, t := range resolveTargets(cfg) {
+		targetsByName[t.Name] = t
+	}
+
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := bucketsForTarget(context.Background(), client, targetsByName[name].BucketGlob)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			policy, err := client.GetBucketPolicy(context.Background(), bucket)
+			if err != nil {
+				logger.Warn("error getting bucket policy", "target", name, "bucket", bucket, "error", err)
+				continue
+			}
+			if policy == "" {
+				fmt.Printf("[%s] Bucket %s: no policy set\n", name, bucket)
+				continue
+			}
+			fmt.Printf("[%s] Bucket %s policy:\n%s\n", name, bucket, policy)
+		}
+		return nil
+	})
+}
+
+func policyApply(clients map[string]MinioClientInterface, cfg *Config, template, policyFilePath string) error {
+	targetsByName := make(map[string]TargetConfig)
+	for _, t := range resolveTargets(cfg) {
+		targetsByName[t.Name] = t
+	}
+
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := bucketsForTarget(context.Background(), client, targetsByName[name].BucketGlob)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			doc, err := resolveBucketPolicyDocument(template, policyFilePath, bucket)
+			if err != nil {
+				return err
+			}
+
+			if doc == "" {
+				if err := client.DeleteBucketPolicy(context.Background(), bucket); err != nil {
+					logger.Error("error removing bucket policy", "target", name, "bucket", bucket, "error", err)
+					continue
+				}
+				logger.Info("bucket policy removed", "target", name, "bucket", bucket)
+				continue
+			}
+
+			if err := client.SetBucketPolicy(context.Background(), bucket, doc); err != nil {
+				logger.Error("error applying bucket policy", "target", name, "bucket", bucket, "error", err)
+				continue
+			}
+			logger.Info("bucket policy applied", "target", name, "bucket", bucket, "template", template)
+		}
+		return nil
+	})
+}
+
+func policyCheck(clients map[string]MinioClientInterface, cfg *Config, template, policyFilePath string) error {
+	targetsByName := make(map[string]TargetConfig)
+	for _, t := range resolveTargets(cfg) {
+		targetsByName[t.Name] = t
+	}
+
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := bucketsForTarget(context.Background(), client, targetsByName[name].BucketGlob)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			want, err := resolveBucketPolicyDocument(template, policyFilePath, bucket)
+			if err != nil {
+				return err
+			}
+
+			got, err := client.GetBucketPolicy(context.Background(), bucket)
+			if err != nil {
+				logger.Warn("error getting bucket policy", "target", name, "bucket", bucket, "error", err)
+				continue
+			}
+
+			equal, err := policyDocumentsEqual(got, want)
+			if err != nil {
+				logger.Warn("error comparing bucket policy", "target", name, "bucket", bucket, "error", err)
+				continue
+			}
+			if equal {
+				logger.Info("bucket policy matches expected template", "target", name, "bucket", bucket)
+			} else {
+				logger.Warn("bucket policy differs from expected template", "target", name, "bucket", bucket)
+			}
+		}
+		return nil
+	})
+}
+
+// resolveBucketPolicyDocument возвращает JSON-документ bucket policy для
+// бакета: либо содержимое --policy-file как есть, либо сгенерированный из
+// встроенного шаблона (none/download/upload/public).
+func resolveBucketPolicyDocument(template, policyFilePath, bucket string) (string, error) {
+	if policyFilePath != "" {
+		return loadPolicyDocumentFile(policyFilePath)
+	}
+	return bucketPolicyDocument(template, bucket)
+}
+
+func loadPolicyDocumentFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading policy file: %w", err)
+	}
+	return string(b), nil
+}
+
+// policyDocumentsEqual сравнивает два JSON-документа bucket policy
+// семантически, игнорируя форматирование и порядок ключей. Пустой документ
+// (нет политики) считается равным только пустому ожидаемому значению.
+func policyDocumentsEqual(got, want string) (bool, error) {
+	if got == "" || want == "" {
+		return got == want, nil
+	}
+
+	var gotDoc, wantDoc interface{}
+	if err := json.Unmarshal([]byte(got), &gotDoc); err != nil {
+		return false, fmt.Errorf("invalid existing policy JSON: %w", err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantDoc); err != nil {
+		return false, fmt.Errorf("invalid expected policy JSON: %w", err)
+	}
+	return reflect.DeepEqual(gotDoc, wantDoc), nil
+}
+
+// bucketPolicyDocument строит AWS-совместимый JSON bucket policy для
+// одного из встроенных шаблонов анонимного доступа.
+func bucketPolicyDocument(template, bucket string) (string, error) {
+	resource := fmt.Sprintf("arn:aws:s3:::%s", bucket)
+	objects := fmt.Sprintf("arn:aws:s3:::%s/*", bucket)
+	anyPrincipal := map[string]interface{}{"AWS": []string{"*"}}
+
+	var statements []map[string]interface{}
+	switch template {
+	case "", PolicyTemplateNone:
+		return "", nil
+	case PolicyTemplateDownload:
+		statements = []map[string]interface{}{
+			{
+				"Effect":    "Allow",
+				"Principal": anyPrincipal,
+				"Action":    []string{"s3:GetBucketLocation", "s3:ListBucket"},
+				"Resource":  []string{resource},
+			},
+			{
+				"Effect":    "Allow",
+				"Principal": anyPrincipal,
+				"Action":    []string{"s3:GetObject"},
+				"Resource":  []string{objects},
+			},
+		}
+	case PolicyTemplateUpload:
+		statements = []map[string]interface{}{
+			{
+				"Effect":    "Allow",
+				"Principal": anyPrincipal,
+				"Action":    []string{"s3:GetBucketLocation", "s3:ListBucketMultipartUploads"},
+				"Resource":  []string{resource},
+			},
+			{
+				"Effect":    "Allow",
+				"Principal": anyPrincipal,
+				"Action":    []string{"s3:AbortMultipartUpload", "s3:DeleteObject", "s3:ListMultipartUploadParts", "s3:PutObject"},
+				"Resource":  []string{objects},
+			},
+		}
+	case PolicyTemplatePublic:
+		statements = []map[string]interface{}{
+			{
+				"Effect":    "Allow",
+				"Principal": anyPrincipal,
+				"Action":    []string{"s3:GetBucketLocation", "s3:ListBucket", "s3:ListBucketMultipartUploads"},
+				"Resource":  []string{resource},
+			},
+			{
+				"Effect":    "Allow",
+				"Principal": anyPrincipal,
+				"Action":    []string{"s3:AbortMultipartUpload", "s3:DeleteObject", "s3:GetObject", "s3:ListMultipartUploadParts", "s3:PutObject"},
+				"Resource":  []string{objects},
+			},
+		}
+	default:
+		return "", fmt.Errorf("unknown policy template %q", template)
+	}
+
+	doc := map[string]interface{}{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding policy document: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketPolicyDocument(t *testing.T) {
+	t.Run("None template yields no policy", func(t *testing.T) {
+		doc, err := bucketPolicyDocument(PolicyTemplateNone, "my-bucket")
+		assert.NoError(t, err)
+		assert.Empty(t, doc)
+	})
+
+	t.Run("Download template scopes GetObject to the bucket", func(t *testing.T) {
+		doc, err := bucketPolicyDocument(PolicyTemplateDownload, "my-bucket")
+		assert.NoError(t, err)
+		assert.Contains(t, doc, "arn:aws:s3:::my-bucket/*")
+		assert.Contains(t, doc, "s3:GetObject")
+		assert.NotContains(t, doc, "s3:PutObject")
+	})
+
+	t.Run("Unknown template is an error", func(t *testing.T) {
+		_, err := bucketPolicyDocument("bogus", "my-bucket")
+		assert.Error(t, err)
+	})
+}
+
+func TestPolicyDocumentsEqual(t *testing.T) {
+	t.Run("Semantically equal JSON with different formatting", func(t *testing.T) {
+		equal, err := policyDocumentsEqual(`{"a":1,"b":2}`, "{\n  \"b\": 2,\n  \"a\": 1\n}")
+		assert.NoError(t, err)
+		assert.True(t, equal)
+	})
+
+	t.Run("Empty strings are only equal to each other", func(t *testing.T) {
+		equal, err := policyDocumentsEqual("", "")
+		assert.NoError(t, err)
+		assert.True(t, equal)
+
+		equal, err = policyDocumentsEqual("", `{"a":1}`)
+		assert.NoError(t, err)
+		assert.False(t, equal)
+	})
+}
+
+func TestResolveBucketPolicyDocument(t *testing.T) {
+	t.Run("Custom policy file wins over template", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policy.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"Version":"2012-10-17"}`), 0o600))
+
+		doc, err := resolveBucketPolicyDocument(PolicyTemplatePublic, path, "my-bucket")
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"Version":"2012-10-17"}`, doc)
+	})
+
+	t.Run("Falls back to template when no file given", func(t *testing.T) {
+		doc, err := resolveBucketPolicyDocument(PolicyTemplateUpload, "", "my-bucket")
+		assert.NoError(t, err)
+		assert.Contains(t, doc, "s3:PutObject")
+	})
+}
+
+func TestPolicyGetHonorsTargetBucketGlob(t *testing.T) {
+	mockClient := new(MockMinioClient)
+	cfg := &Config{Targets: []TargetConfig{{Name: "default", BucketGlob: "prod-*"}}}
+
+	buckets := []minio.BucketInfo{
+		{Name: "prod-a"},
+		{Name: "staging-a"},
+	}
+	mockClient.On("ListBuckets", context.Background()).Return(buckets, nil)
+	mockClient.On("GetBucketPolicy", context.Background(), "prod-a").Return("", nil)
+
+	err := policyGet(map[string]MinioClientInterface{"default": mockClient}, cfg)
+	assert.NoError(t, err)
+	mockClient.AssertNotCalled(t, "GetBucketPolicy", context.Background(), "staging-a")
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduleConfig задает cron-расписание (5 полей, как в Kubernetes CronJob)
+// для команд, выполняемых демоном, и настройки leader election на случай
+// нескольких реплик демона.
+type ScheduleConfig struct {
+	Check          string               `mapstructure:"check"`
+	Apply          string               `mapstructure:"apply"`
+	Clean          string               `mapstructure:"clean"`
+	LeaderElection LeaderElectionConfig `mapstructure:"leaderElection"`
+}
+
+// LeaderElectionConfig включает leader election через lock-объект в MinIO,
+// чтобы несколько реплик демона не выполняли одну и ту же команду
+// одновременно на общем кластере.
+type LeaderElectionConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Bucket       string `mapstructure:"bucket"`
+	LockObject   string `mapstructure:"lockObject"`
+	TTLSeconds   int    `mapstructure:"ttlSeconds"`
+	RenewSeconds int    `mapstructure:"renewSeconds"`
+}
+
+var (
+	rootCtx   = context.Background()
+	rootCtxMu sync.RWMutex
+)
+
+// rootContext возвращает контекст, от которого наследуются долгоживущие
+// операции вроде cleanSingleBucket. Вне демона это context.Background,
+// а во время работы демона - контекст, отменяемый при получении
+// SIGTERM/SIGINT, что позволяет штатно прервать выполняющийся прогон при
+// остановке демона.
+func rootContext() context.Context {
+	rootCtxMu.RLock()
+	defer rootCtxMu.RUnlock()
+	return rootCtx
+}
+
+func setRootContext(ctx context.Context) {
+	rootCtxMu.Lock()
+	rootCtx = ctx
+	rootCtxMu.Unlock()
+}
+
+// runDaemon запускает check/apply/clean по cron-расписанию из
+// cfg.Cleaner.Schedule. С --once расписание выполняется один раз немедленно,
+// после чего функция возвращается без запуска планировщика.
+func runDaemon(clients map[string]MinioClientInterface, cfg *Config, once bool) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+	setRootContext(ctx)
+	defer setRootContext(context.Background())
+
+	var elector *leaderElector
+	le := cfg.Cleaner.Schedule.LeaderElection
+	if le.Enabled {
+		target, client, err := leaderElectionClient(clients)
+		if err != nil {
+			return err
+		}
+		elector = newLeaderElector(client, le)
+		// Первая попытка выполняется синхронно: иначе --once мог бы запустить
+		// job.run() раньше, чем фоновая elector.run(ctx) успеет сделать первый
+		// PutObject, и isLeader() оказался бы false на каждом прогоне.
+		elector.tryAcquireOrRenew(ctx)
+		go elector.run(ctx)
+		logger.Info("leader election enabled", "target", target, "bucket", le.Bucket, "lockObject", le.LockObject, "leader", elector.isLeader())
+	}
+
+	jobs := []struct {
+		name string
+		spec string
+		run  func()
+	}{
+		{"check", cfg.Cleaner.Schedule.Check, func() {
+			runScheduledCommand(elector, "check", func() error { return checkLifecycle(clients, cfg, nil) })
+		}},
+		{"apply", cfg.Cleaner.Schedule.Apply, func() {
+			runScheduledCommand(elector, "apply", func() error { return applyLifecycle(clients, cfg, nil) })
+		}},
+		{"clean", cfg.Cleaner.Schedule.Clean, func() {
+			runScheduledCommand(elector, "clean", func() error { return cleanVersions(clients, cfg, nil) })
+		}},
+	}
+
+	if once {
+		for _, job := range jobs {
+			if job.spec == "" {
+				continue
+			}
+			logger.Info("running scheduled command once", "command", job.name)
+			job.run()
+		}
+		return nil
+	}
+
+	c := cron.New()
+	for _, job := range jobs {
+		if job.spec == "" {
+			continue
+		}
+		if _, err := c.AddFunc(job.spec, job.run); err != nil {
+			return fmt.Errorf("invalid schedule for %s: %w", job.name, err)
+		}
+	}
+
+	c.Start()
+	logger.Info("daemon started")
+	<-ctx.Done()
+	logger.Info("daemon shutting down")
+	stopCtx := c.Stop()
+	<-stopCtx.Done()
+	logger.Info("daemon stopped")
+	return nil
+}
+
+// runScheduledCommand выполняет fn, если данная реплика демона является
+// лидером (или leader election отключен), и логирует ошибки, не прерывая
+// расписание.
+func runScheduledCommand(elector *leaderElector, name string, fn func() error) {
+	if elector != nil && !elector.isLeader() {
+		logger.Info("skipping scheduled command: not leader", "command", name)
+		return
+	}
+	if err := fn(); err != nil {
+		logger.Error("scheduled command failed", "command", name, "error", err)
+	}
+}
+
+// leaderElectionClient выбирает клиента, используемого для управления
+// lock-объектом leader election. При нескольких целях lock хранится у
+// первой по имени - координация между независимыми кластерами не
+// предполагается, каждый из них предполагается обслуживаемым отдельным
+// набором реплик демона.
+func leaderElectionClient(clients map[string]MinioClientInterface) (string, MinioClientInterface, error) {
+	if len(clients) == 0 {
+		return "", nil, fmt.Errorf("no targets configured for leader election")
+	}
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names[0], clients[names[0]], nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunDaemonOnceAcquiresLeadershipSynchronously(t *testing.T) {
+	mockClient := new(MockMinioClient)
+	mockClient.On("PutObject", mock.Anything, "ops", defaultLockObject, mock.Anything, mock.Anything, mock.Anything).
+		Return(minio.UploadInfo{ETag: "etag-1"}, nil)
+	mockClient.On("ListBuckets", context.Background()).Return([]minio.BucketInfo{}, nil)
+
+	cfg := &Config{}
+	cfg.Cleaner.Schedule.Check = "@every 1m"
+	cfg.Cleaner.Schedule.LeaderElection = LeaderElectionConfig{Enabled: true, Bucket: "ops"}
+
+	err := runDaemon(map[string]MinioClientInterface{"default": mockClient}, cfg, true)
+	assert.NoError(t, err)
+
+	// Без синхронного первого acquire "check" был бы пропущен как
+	// "not leader", и ListBuckets никогда бы не вызвался.
+	mockClient.AssertCalled(t, "ListBuckets", context.Background())
+}
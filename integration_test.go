@@ -149,27 +149,32 @@ func TestIntegrationLifecycleOperations(t *testing.T) {
 		},
 	}
 
-	client, err := newMinioClient(cfg)
+	clients, err := newMinioClients(cfg)
 	assert.NoError(t, err)
+	client := clients["default"]
 
 	// Тестовый бакет
 	testBucket := "test-bucket"
 
 	t.Run("Create and test bucket lifecycle", func(t *testing.T) {
 		// Создаем бакет
-		mClient := client.(*minio.Client)
+		mClient := client.(*minioClientAdapter)
 		err := mClient.MakeBucket(ctx, testBucket, minio.MakeBucketOptions{})
 		assert.NoError(t, err)
 
 		// Проверяем отсутствие политики
-		checkSingleBucket(client, testBucket)
+		checkSingleBucket("default", client, cfg, testBucket, nil)
 
 		// Применяем политику
-		processBucket(client, testBucket)
+		processBucket("default", client, cfg, testBucket, nil)
 
 		// Проверяем обновленную политику
 		lc, err := client.GetBucketLifecycle(ctx, testBucket)
 		assert.NoError(t, err)
-		assert.True(t, hasCorrectPolicy(lc))
+		rules, err := resolvePolicyRules(cfg, testBucket)
+		assert.NoError(t, err)
+		ok, err := hasCorrectPolicy(rules, lc)
+		assert.NoError(t, err)
+		assert.True(t, ok)
 	})
 }
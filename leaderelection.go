@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	defaultLockObject = "leader.lock"
+	defaultLockTTL    = 30 * time.Second
+	defaultLockRenew  = 10 * time.Second
+)
+
+// leaderElector реализует leader election поверх MinIO через lock-объект,
+// создаваемый с условием If-None-Match (PutObjectOptions.SetMatchETagExcept
+// ("*")), гарантирующим, что только одна реплика демона сможет создать его
+// первой. Удержание лидерства продлевается через условную перезапись по
+// ETag (SetMatchETag), так что реплика, у которой кто-то перехватил lock,
+// сама обнаруживает это при следующем продлении.
+type leaderElector struct {
+	client MinioClientInterface
+	bucket string
+	object string
+	ttl    time.Duration
+	renew  time.Duration
+	holder string
+
+	mu     sync.RWMutex
+	leader bool
+	etag   string
+}
+
+func newLeaderElector(client MinioClientInterface, cfg LeaderElectionConfig) *leaderElector {
+	lockObject := cfg.LockObject
+	if lockObject == "" {
+		lockObject = defaultLockObject
+	}
+	ttl := defaultLockTTL
+	if cfg.TTLSeconds > 0 {
+		ttl = time.Duration(cfg.TTLSeconds) * time.Second
+	}
+	renew := defaultLockRenew
+	if cfg.RenewSeconds > 0 {
+		renew = time.Duration(cfg.RenewSeconds) * time.Second
+	}
+
+	hostname, _ := os.Hostname()
+	return &leaderElector{
+		client: client,
+		bucket: cfg.Bucket,
+		object: lockObject,
+		ttl:    ttl,
+		renew:  renew,
+		holder: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}
+}
+
+func (le *leaderElector) isLeader() bool {
+	le.mu.RLock()
+	defer le.mu.RUnlock()
+	return le.leader
+}
+
+// run периодически пытается получить или продлить лидерство до отмены ctx.
+func (le *leaderElector) run(ctx context.Context) {
+	le.tryAcquireOrRenew(ctx)
+
+	ticker := time.NewTicker(le.renew)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			le.tryAcquireOrRenew(ctx)
+		}
+	}
+}
+
+func (le *leaderElector) tryAcquireOrRenew(ctx context.Context) {
+	if le.isLeader() {
+		le.renewLock(ctx)
+		return
+	}
+	le.acquireLock(ctx)
+}
+
+func (le *leaderElector) renewLock(ctx context.Context) {
+	le.mu.RLock()
+	etag := le.etag
+	le.mu.RUnlock()
+
+	opts := minio.PutObjectOptions{ContentType: "text/plain"}
+	opts.SetMatchETag(etag)
+
+	info, err := le.client.PutObject(ctx, le.bucket, le.object, strings.NewReader(le.holder), int64(len(le.holder)), opts)
+	if err != nil {
+		logger.Warn("lost leadership while renewing lock", "holder", le.holder, "error", err)
+		le.setLeader(false, "")
+		return
+	}
+	le.setLeader(true, info.ETag)
+}
+
+func (le *leaderElector) acquireLock(ctx context.Context) {
+	opts := minio.PutObjectOptions{ContentType: "text/plain"}
+	opts.SetMatchETagExcept("*")
+
+	info, err := le.client.PutObject(ctx, le.bucket, le.object, strings.NewReader(le.holder), int64(len(le.holder)), opts)
+	if err == nil {
+		logger.Info("acquired leader lock", "holder", le.holder)
+		le.setLeader(true, info.ETag)
+		return
+	}
+
+	le.stealExpiredLock(ctx)
+}
+
+// stealExpiredLock удаляет lock-объект, если он не обновлялся дольше TTL, и
+// пытается захватить его заново. Окно между удалением и повторным созданием
+// не атомарно: при гонке с другой репликой лидерство получит та, чей
+// SetMatchETagExcept("*") выполнится первым, а проигравшая обнаружит это на
+// следующей попытке.
+func (le *leaderElector) stealExpiredLock(ctx context.Context) {
+	info, err := le.client.StatObject(ctx, le.bucket, le.object, minio.StatObjectOptions{})
+	if err != nil {
+		logger.Warn("error checking leader lock", "error", err)
+		return
+	}
+	if time.Since(info.LastModified) < le.ttl {
+		return
+	}
+
+	logger.Warn("leader lock expired, attempting takeover", "holder", le.holder, "lastModified", info.LastModified)
+	if err := le.client.RemoveObject(ctx, le.bucket, le.object, minio.RemoveObjectOptions{}); err != nil {
+		logger.Warn("error removing expired leader lock", "error", err)
+		return
+	}
+
+	opts := minio.PutObjectOptions{ContentType: "text/plain"}
+	opts.SetMatchETagExcept("*")
+	newInfo, err := le.client.PutObject(ctx, le.bucket, le.object, strings.NewReader(le.holder), int64(len(le.holder)), opts)
+	if err != nil {
+		return
+	}
+	le.setLeader(true, newInfo.ETag)
+}
+
+func (le *leaderElector) setLeader(leader bool, etag string) {
+	le.mu.Lock()
+	le.leader = leader
+	le.etag = etag
+	le.mu.Unlock()
+}
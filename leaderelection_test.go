@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLeaderElectorAcquireLock(t *testing.T) {
+	t.Run("Acquires lock when object does not exist", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		le := newLeaderElector(mockClient, LeaderElectionConfig{Bucket: "ops", LockObject: "leader.lock"})
+
+		mockClient.On("PutObject", mock.Anything, "ops", "leader.lock", mock.Anything, mock.Anything, mock.Anything).
+			Return(minio.UploadInfo{ETag: "etag-1"}, nil)
+
+		le.acquireLock(context.Background())
+		assert.True(t, le.isLeader())
+	})
+
+	t.Run("Steals an expired lock", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		le := newLeaderElector(mockClient, LeaderElectionConfig{Bucket: "ops", LockObject: "leader.lock", TTLSeconds: 1})
+
+		mockClient.On("PutObject", mock.Anything, "ops", "leader.lock", mock.Anything, mock.Anything, mock.Anything).
+			Return(minio.UploadInfo{}, minio.ErrorResponse{Code: "PreconditionFailed"}).Once()
+		mockClient.On("StatObject", mock.Anything, "ops", "leader.lock", mock.Anything).
+			Return(minio.ObjectInfo{LastModified: time.Now().Add(-time.Hour)}, nil)
+		mockClient.On("RemoveObject", mock.Anything, "ops", "leader.lock", mock.Anything).Return(nil)
+		mockClient.On("PutObject", mock.Anything, "ops", "leader.lock", mock.Anything, mock.Anything, mock.Anything).
+			Return(minio.UploadInfo{ETag: "etag-2"}, nil).Once()
+
+		le.acquireLock(context.Background())
+		assert.True(t, le.isLeader())
+	})
+
+	t.Run("Leaves lock alone when still fresh", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		le := newLeaderElector(mockClient, LeaderElectionConfig{Bucket: "ops", LockObject: "leader.lock", TTLSeconds: 3600})
+
+		mockClient.On("PutObject", mock.Anything, "ops", "leader.lock", mock.Anything, mock.Anything, mock.Anything).
+			Return(minio.UploadInfo{}, minio.ErrorResponse{Code: "PreconditionFailed"})
+		mockClient.On("StatObject", mock.Anything, "ops", "leader.lock", mock.Anything).
+			Return(minio.ObjectInfo{LastModified: time.Now()}, nil)
+
+		le.acquireLock(context.Background())
+		assert.False(t, le.isLeader())
+		mockClient.AssertNotCalled(t, "RemoveObject", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLeaderElectorRenewLock(t *testing.T) {
+	t.Run("Renews successfully", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		le := newLeaderElector(mockClient, LeaderElectionConfig{Bucket: "ops"})
+		le.setLeader(true, "etag-1")
+
+		mockClient.On("PutObject", mock.Anything, "ops", defaultLockObject, mock.Anything, mock.Anything, mock.Anything).
+			Return(minio.UploadInfo{ETag: "etag-2"}, nil)
+
+		le.renewLock(context.Background())
+		assert.True(t, le.isLeader())
+	})
+
+	t.Run("Steps down when lock was taken over", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		le := newLeaderElector(mockClient, LeaderElectionConfig{Bucket: "ops"})
+		le.setLeader(true, "etag-1")
+
+		mockClient.On("PutObject", mock.Anything, "ops", defaultLockObject, mock.Anything, mock.Anything, mock.Anything).
+			Return(minio.UploadInfo{}, errors.New("etag mismatch"))
+
+		le.renewLock(context.Background())
+		assert.False(t, le.isLeader())
+	})
+}
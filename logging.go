@@ -0,0 +1,24 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// logger - структурированный логгер, используемый cleanSingleBucket,
+// processBucket и checkSingleBucket, чтобы прогоны можно было агрегировать
+// и фильтровать в системах сбора логов. Формат настраивается флагом
+// --log-format (text по умолчанию, json для скрейпинга в Kubernetes).
+var logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+// initLogger переконфигурирует глобальный logger под запрошенный формат.
+func initLogger(format string) {
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, nil)
+	default:
+		handler = slog.NewTextHandler(os.Stdout, nil)
+	}
+	logger = slog.New(handler)
+}
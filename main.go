@@ -4,8 +4,12 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,20 +28,45 @@ type MinioClientInterface interface {
 	BucketExists(ctx context.Context, bucketName string) (bool, error)
 	ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
 	RemoveObjects(ctx context.Context, bucketName string, objectsCh <-chan minio.ObjectInfo, opts minio.RemoveObjectsOptions) <-chan minio.RemoveObjectError
+	GetBucketPolicy(ctx context.Context, bucketName string) (string, error)
+	SetBucketPolicy(ctx context.Context, bucketName string, policy string) error
+	DeleteBucketPolicy(ctx context.Context, bucketName string) error
+	SetBucketObjectLockConfig(ctx context.Context, bucketName string, mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit) error
+	GetObjectLockConfig(ctx context.Context, bucketName string) (objectLock string, mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit, err error)
+	PutObjectRetention(ctx context.Context, bucketName, objectName string, opts minio.PutObjectRetentionOptions) error
+	PutObjectLegalHold(ctx context.Context, bucketName, objectName string, opts minio.PutObjectLegalHoldOptions) error
+	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	StatObject(ctx context.Context, bucketName, objectName string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error
 }
 
 const (
-	defaultConfigPath = "."
-	appName           = "minio-cleaner"
+	defaultConfigPath  = "."
+	appName            = "minio-cleaner"
+	defaultParallelism = 4
 )
 
 var (
-	version    = "dev" // задается при сборке через -ldflags
-	bucketName string  // Добавим переменную для хранения имени бакета
-	dryRun     bool    // Новый флаг
-
+	version     = "dev" // задается при сборке через -ldflags
+	bucketName  string  // Добавим переменную для хранения имени бакета
+	dryRun      bool    // Новый флаг
+	parallelism int     // Количество целей (targets), обрабатываемых одновременно
 )
 
+// TargetConfig описывает один MinIO endpoint (кластер/тенант), который
+// должен обрабатываться наравне с остальными.
+type TargetConfig struct {
+	Name      string `mapstructure:"name"`
+	Endpoint  string `mapstructure:"endpoint"`
+	AccessKey string `mapstructure:"accessKey"`
+	SecretKey string `mapstructure:"secretKey"`
+	UseSSL    bool   `mapstructure:"useSSL"`
+	Region    string `mapstructure:"region"`
+	// BucketGlob, если задан, ограничивает обработку бакетами, чье имя
+	// совпадает с шаблоном (см. path/filepath.Match).
+	BucketGlob string `mapstructure:"bucketGlob"`
+}
+
 // Config хранит настройки подключения к MinIO
 type Config struct {
 	Minio struct {
@@ -46,21 +75,58 @@ type Config struct {
 		SecretKey string `mapstructure:"secretKey"`
 		UseSSL    bool   `mapstructure:"useSSL"`
 	} `mapstructure:"minio"`
+	// Targets позволяет описать несколько независимых MinIO-кластеров
+	// (мульти-тенант режим). Если список пуст, используется блок Minio
+	// как единственная цель с именем "default".
+	Targets []TargetConfig `mapstructure:"targets"`
 	Cleaner struct {
 		MaxObjectsPerRun int `mapstructure:"maxObjectsPerRun"`
+		// Policies объявляет именованные наборы правил lifecycle. Ключ -
+		// имя политики, значение - список правил, применяемых вместе.
+		Policies map[string][]PolicyRuleConfig `mapstructure:"policies"`
+		// DefaultPolicy - имя политики из Policies, применяемой к бакетам,
+		// не попавшим ни под одно правило из BucketPolicies. Если пусто,
+		// используется политика "auto-clean-versions" из Policies, а если ее
+		// нет - defaultPolicyRules (старое поведение auto-clean-versions).
+		DefaultPolicy string `mapstructure:"defaultPolicy"`
+		// BucketPolicies позволяет переопределить политику для бакетов,
+		// чьи имена совпадают с заданным шаблоном.
+		BucketPolicies []BucketPolicyOverride `mapstructure:"bucketPolicies"`
+		// Retention описывает режим object-lock retention, применяемый и
+		// проверяемый командой "retention".
+		Retention RetentionConfig `mapstructure:"retention"`
+		// Schedule задает cron-расписание команд для команды "daemon".
+		Schedule ScheduleConfig `mapstructure:"schedule"`
 	} `mapstructure:"cleaner"`
 }
 
 func main() {
 	var configFile string
+	var policyFilePath string
 	var showVersion bool
 	var showHelp bool
+	var metricsAddr string
+	var logFormat string
+	var healthzBucket string
+	var policyTemplate string
+	var once bool
+	var reportFormat string
+	var reportTopN int
 
 	flag.StringVar(&configFile, "config", "", "Path to config file")
 	flag.BoolVar(&showVersion, "version", false, "Show version and exit")
 	flag.StringVar(&bucketName, "bucket", "", "Specific bucket name to process") // Добавим новый флаг
 	flag.BoolVar(&showHelp, "help", false, "Show this help message")
 	flag.BoolVar(&dryRun, "dry-run", false, "Simulate cleanup without actual deletion")
+	flag.IntVar(&parallelism, "parallelism", defaultParallelism, "Number of targets processed concurrently")
+	flag.StringVar(&policyFilePath, "policy-file", "", "Path to a YAML file declaring cleaner.policies entries; for 'policy' commands, a custom bucket policy JSON document")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "Address to serve Prometheus /metrics and /healthz on (e.g. :9100); disabled if empty")
+	flag.StringVar(&logFormat, "log-format", "text", "Structured log output format: text or json")
+	flag.StringVar(&healthzBucket, "healthz-bucket", "", "Canary bucket used by /healthz to verify MinIO connectivity")
+	flag.StringVar(&policyTemplate, "policy-template", PolicyTemplateNone, "Built-in bucket policy template for 'policy' command: none, download, upload, or public")
+	flag.BoolVar(&once, "once", false, "For 'daemon': run the configured schedule immediately once, then exit")
+	flag.StringVar(&reportFormat, "report-format", "", "Render a check/apply/clean report in this format after the run: json, csv, or table; disabled if empty")
+	flag.IntVar(&reportTopN, "report-topn", 5, "Number of largest noncurrent objects to include per bucket in the report")
 
 	// Настраиваем кастомный вывод помощи
 	flag.Usage = func() {
@@ -70,6 +136,9 @@ func main() {
 		fmt.Println("  check   - Check lifecycle policies")
 		fmt.Println("  apply   - Apply default lifecycle policies")
 		fmt.Println("  clean   - Clean non-current versions of all objects") // Добавлено
+		fmt.Println("  policy get|apply|check - Manage bucket policies (see --policy-template, --policy-file)")
+		fmt.Println("  retention apply|check  - Manage object-lock retention (see cleaner.retention config)")
+		fmt.Println("  daemon                 - Run check/apply/clean on the cron schedule in cleaner.schedule (--once to run once)")
 		fmt.Println("\nOptions:")
 		flag.PrintDefaults()
 	}
@@ -83,14 +152,33 @@ func main() {
 		os.Exit(0)
 	}
 
+	initLogger(logFormat)
+
 	cfg, err := loadConfig(configFile)
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
 
-	minioClient, err := newMinioClient(cfg)
+	if policyFilePath != "" {
+		policies, err := loadPolicyFile(policyFilePath)
+		if err != nil {
+			log.Fatalf("Error loading policy file: %v", err)
+		}
+		mergePolicies(cfg, policies)
+	}
+
+	clients, err := newMinioClients(cfg)
 	if err != nil {
-		log.Fatalf("Error creating MinIO client: %v", err)
+		log.Fatalf("Error creating MinIO clients: %v", err)
+	}
+
+	if metricsAddr != "" {
+		srv := startMetricsServer(metricsAddr, clients, healthzBucket)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(ctx)
+		}()
 	}
 
 	args := flag.Args()
@@ -99,19 +187,38 @@ func main() {
 		log.Fatal("\nError: command is required")
 	}
 
+	reporter := NewReporter(reportTopN)
+
+	var cmdErr error
 	switch args[0] {
 	case "list":
-		listBuckets(minioClient)
+		cmdErr = listBuckets(clients)
 	case "check":
-		checkLifecycle(minioClient)
+		cmdErr = checkLifecycle(clients, cfg, reporter)
 	case "apply":
-		applyLifecycle(minioClient)
+		cmdErr = applyLifecycle(clients, cfg, reporter)
 	case "clean": // Добавлен новый кейс
-		cleanVersions(minioClient, cfg)
+		cmdErr = cleanVersions(clients, cfg, reporter)
+	case "policy":
+		cmdErr = runPolicyCommand(clients, args[1:], cfg, policyTemplate, policyFilePath)
+	case "retention":
+		cmdErr = runRetentionCommand(clients, args[1:], cfg)
+	case "daemon":
+		cmdErr = runDaemon(clients, cfg, once)
 	default:
 		flag.Usage()
 		log.Fatalf("\nError: unknown command: %s", args[0])
 	}
+
+	if reportFormat != "" {
+		if err := reporter.Render(os.Stdout, reportFormat); err != nil {
+			log.Printf("Error rendering report: %v", err)
+		}
+	}
+
+	if cmdErr != nil {
+		log.Fatalf("Error: %v", cmdErr)
+	}
 }
 
 func loadConfig(configPath string) (*Config, error) {
@@ -154,206 +261,379 @@ func loadConfig(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
-func newMinioClient(cfg *Config) (MinioClientInterface, error) {
-	return minio.New(cfg.Minio.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.Minio.AccessKey, cfg.Minio.SecretKey, ""),
-		Secure: cfg.Minio.UseSSL,
-	})
+// resolveTargets возвращает список целей для обработки. Если cfg.Targets
+// не задан, единственной целью становится блок Minio под именем "default" -
+// это сохраняет обратную совместимость с одно-кластерной конфигурацией.
+func resolveTargets(cfg *Config) []TargetConfig {
+	if len(cfg.Targets) > 0 {
+		return cfg.Targets
+	}
+	return []TargetConfig{
+		{
+			Name:      "default",
+			Endpoint:  cfg.Minio.Endpoint,
+			AccessKey: cfg.Minio.AccessKey,
+			SecretKey: cfg.Minio.SecretKey,
+			UseSSL:    cfg.Minio.UseSSL,
+		},
+	}
 }
 
-func listBuckets(client MinioClientInterface) {
-	buckets, err := client.ListBuckets(context.Background())
-	if err != nil {
-		log.Fatalf("Error listing buckets: %v", err)
+// newMinioClients создает по одному MinIO-клиенту на каждую цель из конфига.
+func newMinioClients(cfg *Config) (map[string]MinioClientInterface, error) {
+	targets := resolveTargets(cfg)
+	clients := make(map[string]MinioClientInterface, len(targets))
+
+	for _, t := range targets {
+		opts := &minio.Options{
+			Creds:  credentials.NewStaticV4(t.AccessKey, t.SecretKey, ""),
+			Secure: t.UseSSL,
+		}
+		if t.Region != "" {
+			opts.Region = t.Region
+		}
+
+		client, err := minio.New(t.Endpoint, opts)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", t.Name, err)
+		}
+		clients[t.Name] = &minioClientAdapter{Client: client}
+	}
+
+	return clients, nil
+}
+
+// forEachTarget выполняет fn для каждой пары (имя цели, клиент) из clients,
+// используя пул воркеров ограниченный parallelism. Ошибки, возвращенные fn,
+// логируются с тегом цели и агрегируются в единую ошибку.
+func forEachTarget(clients map[string]MinioClientInterface, fn func(name string, client MinioClientInterface) error) error {
+	limit := parallelism
+	if limit <= 0 {
+		limit = defaultParallelism
+	}
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed []string
+
+	for name, client := range clients {
+		name, client := name, client
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(name, client); err != nil {
+				log.Printf("[%s] ❌ %v", name, err)
+				mu.Lock()
+				failed = append(failed, name)
+				mu.Unlock()
+			}
+		}()
 	}
+	wg.Wait()
 
-	fmt.Println("Available buckets:")
-	for _, bucket := range buckets {
-		fmt.Printf("- %s (created: %s)\n", bucket.Name, bucket.CreationDate.Format("2006-01-02"))
+	if len(failed) > 0 {
+		return fmt.Errorf("targets failed: %v", failed)
 	}
+	return nil
 }
 
-func checkLifecycle(client MinioClientInterface) {
+// bucketsForTarget возвращает бакеты цели с учетом bucketName (флаг --bucket)
+// и BucketGlob, заданного для конкретной цели.
+func bucketsForTarget(ctx context.Context, client MinioClientInterface, glob string) ([]string, error) {
 	if bucketName != "" {
-		checkSingleBucket(client, bucketName)
-		return
+		return []string{bucketName}, nil
 	}
 
-	buckets, err := client.ListBuckets(context.Background())
+	buckets, err := client.ListBuckets(ctx)
 	if err != nil {
-		log.Fatalf("Error listing buckets: %v", err)
+		return nil, fmt.Errorf("error listing buckets: %w", err)
 	}
 
-	for _, bucket := range buckets {
-		checkSingleBucket(client, bucket.Name)
+	names := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		if glob != "" {
+			matched, err := filepath.Match(glob, b.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bucket glob %q: %w", glob, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		names = append(names, b.Name)
 	}
+	return names, nil
 }
 
-func checkSingleBucket(client MinioClientInterface, name string) {
+func listBuckets(clients map[string]MinioClientInterface) error {
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := client.ListBuckets(context.Background())
+		if err != nil {
+			return fmt.Errorf("error listing buckets: %w", err)
+		}
+
+		fmt.Printf("[%s] Available buckets:\n", name)
+		for _, bucket := range buckets {
+			fmt.Printf("[%s] - %s (created: %s)\n", name, bucket.Name, bucket.CreationDate.Format("2006-01-02"))
+		}
+		return nil
+	})
+}
+
+func checkLifecycle(clients map[string]MinioClientInterface, cfg *Config, reporter *Reporter) error {
+	targetsByName := make(map[string]TargetConfig)
+	for _, t := range resolveTargets(cfg) {
+		targetsByName[t.Name] = t
+	}
+
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := bucketsForTarget(context.Background(), client, targetsByName[name].BucketGlob)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			checkSingleBucket(name, client, cfg, bucket, reporter)
+		}
+		return nil
+	})
+}
+
+func checkSingleBucket(target string, client MinioClientInterface, cfg *Config, name string, reporter *Reporter) {
+	start := time.Now()
+	defer func() {
+		runDurationSeconds.WithLabelValues("check", name).Observe(time.Since(start).Seconds())
+	}()
+
+	rules, err := resolvePolicyRules(cfg, name)
+	if err != nil {
+		logger.Warn("error resolving policy", "target", target, "bucket", name, "error", err)
+		return
+	}
+
 	lc, err := client.GetBucketLifecycle(context.Background(), name)
 	if err != nil {
 		if minio.ToErrorResponse(err).Code == "NoSuchLifecycleConfiguration" {
-			fmt.Printf("Bucket %s: ❌ No lifecycle policy\n", name)
+			logger.Info("no lifecycle policy", "target", target, "bucket", name)
+			lifecyclePolicyCorrect.WithLabelValues(name).Set(0)
+			reporter.RecordPolicyStatus(name, false)
 			return
 		}
-		fmt.Printf("Bucket %s: ⚠️ Error checking lifecycle: %v\n", name, err)
+		logger.Warn("error checking lifecycle", "target", target, "bucket", name, "error", err)
 		return
 	}
 
-	if hasCorrectPolicy(lc) {
-		fmt.Printf("Bucket %s: ✅ Correct policy exists\n", name)
+	ok, err := hasCorrectPolicy(rules, lc)
+	if err != nil {
+		logger.Warn("error evaluating policy", "target", target, "bucket", name, "error", err)
+		return
+	}
+	if ok {
+		logger.Info("policy is correct", "target", target, "bucket", name)
+		lifecyclePolicyCorrect.WithLabelValues(name).Set(1)
 	} else {
-		fmt.Printf("Bucket %s: ⚠️ Policy exists but not configured properly\n", name)
+		logger.Warn("policy exists but is not configured properly", "target", target, "bucket", name)
+		lifecyclePolicyCorrect.WithLabelValues(name).Set(0)
 	}
+	reporter.RecordPolicyStatus(name, ok)
 }
-func applyLifecycle(client MinioClientInterface) {
-	if bucketName != "" {
-		if !bucketExists(client, bucketName) {
-			log.Fatalf("Bucket %s does not exist", bucketName)
-		}
-		processBucket(client, bucketName)
-		return
-	}
 
-	buckets, err := client.ListBuckets(context.Background())
-	if err != nil {
-		log.Fatalf("Error listing buckets: %v", err)
+func applyLifecycle(clients map[string]MinioClientInterface, cfg *Config, reporter *Reporter) error {
+	targetsByName := make(map[string]TargetConfig)
+	for _, t := range resolveTargets(cfg) {
+		targetsByName[t.Name] = t
 	}
 
-	for _, bucket := range buckets {
-		processBucket(client, bucket.Name)
-	}
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := bucketsForTarget(context.Background(), client, targetsByName[name].BucketGlob)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			if !bucketExists(name, client, bucket) {
+				log.Printf("[%s] Bucket %s does not exist", name, bucket)
+				continue
+			}
+			processBucket(name, client, cfg, bucket, reporter)
+		}
+		return nil
+	})
 }
 
 // Вспомогательная функция для проверки существования бакета
-func bucketExists(client MinioClientInterface, name string) bool {
+func bucketExists(target string, client MinioClientInterface, name string) bool {
 	exists, err := client.BucketExists(context.Background(), name)
 	if err != nil {
-		log.Printf("Error checking bucket existence: %v", err)
+		log.Printf("[%s] Error checking bucket existence: %v", target, err)
 		return false
 	}
 	return exists
 }
 
-func processBucket(client MinioClientInterface, bucketName string) {
+func processBucket(target string, client MinioClientInterface, cfg *Config, bucketName string, reporter *Reporter) {
 	ctx := context.Background()
-	const ruleID = "auto-clean-versions"
+	start := time.Now()
+	defer func() {
+		runDurationSeconds.WithLabelValues("apply", bucketName).Observe(time.Since(start).Seconds())
+	}()
+
+	rules, err := resolvePolicyRules(cfg, bucketName)
+	if err != nil {
+		logger.Warn("error resolving policy", "target", target, "bucket", bucketName, "error", err)
+		return
+	}
+
+	if err := preflightObjectLock(ctx, client, bucketName, rules); err != nil {
+		logger.Error("refusing to apply policy", "target", target, "bucket", bucketName, "error", err)
+		return
+	}
 
 	lc, err := client.GetBucketLifecycle(ctx, bucketName)
 	if err != nil {
 		if minio.ToErrorResponse(err).Code != "NoSuchLifecycleConfiguration" {
-			log.Printf("Bucket %s: ⚠️ Error getting lifecycle: %v", bucketName, err)
+			logger.Warn("error getting lifecycle", "target", target, "bucket", bucketName, "error", err)
 			return
 		}
 
-		// Если политики нет вообще - создаем новую
-		newLC := createDefaultLifecycle()
-		err := client.SetBucketLifecycle(ctx, bucketName, newLC)
+		// Если политики нет вообще - создаем новую из разрешенных правил
+		newLC, err := buildLifecycleConfig(rules)
 		if err != nil {
-			log.Printf("Bucket %s: ❌ Error setting lifecycle: %v", bucketName, err)
+			logger.Warn("error building policy", "target", target, "bucket", bucketName, "error", err)
+			return
+		}
+		if err := client.SetBucketLifecycle(ctx, bucketName, newLC); err != nil {
+			logger.Error("error setting lifecycle", "target", target, "bucket", bucketName, "error", err)
 			return
 		}
-		log.Printf("Bucket %s: ✅ Successfully added lifecycle policy", bucketName)
+		logger.Info("lifecycle policy added", "target", target, "bucket", bucketName)
+		reporter.RecordPolicyStatus(bucketName, true)
 		return
 	}
 
-	// Ищем наше правило в существующей конфигурации
-	var ourRule *lifecycle.Rule
-	for i, rule := range lc.Rules {
-		if rule.ID == ruleID {
-			ourRule = &lc.Rules[i]
-			break
+	changed := false
+	for _, pr := range rules {
+		ok, err := hasCorrectRule(pr, lc)
+		if err != nil {
+			logger.Warn("error evaluating rule", "target", target, "bucket", bucketName, "rule", pr.ID, "error", err)
+			return
+		}
+		if ok {
+			continue
 		}
-	}
 
-	if ourRule == nil {
-		// Если правила нет - добавляем его к существующим
-		lc.Rules = append(lc.Rules, createDefaultLifecycle().Rules[0])
-	} else if !hasCorrectRule(ourRule) {
-		// Если правило есть, но не корректное - удаляем и добавляем заново
-		var removed bool
-		lc, removed = removeRuleByID(lc, ruleID)
-		if !removed {
-			log.Printf("Bucket %s: ⚠️ Failed to remove existing rule", bucketName)
+		// Правило отсутствует или не соответствует политике - пересоздаем его
+		lc, _ = removeRuleByID(lc, pr.ID)
+		rule, err := buildLifecycleRule(pr)
+		if err != nil {
+			logger.Warn("error building rule", "target", target, "bucket", bucketName, "rule", pr.ID, "error", err)
 			return
 		}
-		lc.Rules = append(lc.Rules, createDefaultLifecycle().Rules[0])
-	} else {
-		// Правило уже корректное - ничего не делаем
-		log.Printf("Bucket %s: ✅ Policy already correct", bucketName)
+		lc.Rules = append(lc.Rules, rule)
+		changed = true
+	}
+
+	if !changed {
+		logger.Info("policy already correct", "target", target, "bucket", bucketName)
+		reporter.RecordPolicyStatus(bucketName, true)
 		return
 	}
 
 	// Применяем обновленную конфигурацию
 	if err := client.SetBucketLifecycle(ctx, bucketName, lc); err != nil {
-		log.Printf("Bucket %s: ❌ Error updating lifecycle: %v", bucketName, err)
+		logger.Error("error updating lifecycle", "target", target, "bucket", bucketName, "error", err)
 		return
 	}
-	log.Printf("Bucket %s: ✅ Successfully updated lifecycle policy", bucketName)
+	logger.Info("lifecycle policy updated", "target", target, "bucket", bucketName)
+	reporter.RecordPolicyStatus(bucketName, true)
 }
 
-func createDefaultLifecycle() *lifecycle.Configuration {
-	return &lifecycle.Configuration{
-		Rules: []lifecycle.Rule{
-			{
-				ID:     "auto-clean-versions",
-				Status: "Enabled",
-				NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
-					NoncurrentDays:          lifecycle.ExpirationDays(1),
-					NewerNoncurrentVersions: 1,
-				},
-				Expiration: lifecycle.Expiration{DeleteMarker: true},
-			},
-		},
+// hasCorrectPolicy сообщает, соответствуют ли все правила политики rules
+// своим аналогам в lc (по ID).
+func hasCorrectPolicy(rules []PolicyRuleConfig, lc *lifecycle.Configuration) (bool, error) {
+	for _, pr := range rules {
+		ok, err := hasCorrectRule(pr, lc)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
 	}
+	return true, nil
 }
 
-func hasCorrectPolicy(lc *lifecycle.Configuration) bool {
-	for _, rule := range lc.Rules {
-		if hasCorrectRule(&rule) {
-			return true
+// hasCorrectRule сообщает, присутствует ли в lc правило с ID pr.ID и
+// совпадает ли оно по содержимому с тем, что описано в pr.
+func hasCorrectRule(pr PolicyRuleConfig, lc *lifecycle.Configuration) (bool, error) {
+	var actual *lifecycle.Rule
+	for i, rule := range lc.Rules {
+		if rule.ID == pr.ID {
+			actual = &lc.Rules[i]
+			break
 		}
 	}
-	return false
-}
-func hasCorrectRule(rule *lifecycle.Rule) bool {
-	if rule.Status == "Enabled" &&
-		rule.NoncurrentVersionExpiration.NoncurrentDays == 1 &&
-		rule.NoncurrentVersionExpiration.NewerNoncurrentVersions == 1 &&
-		rule.Expiration.DeleteMarker {
-		return true
-	}
-	return false
-}
-
-func cleanVersions(client MinioClientInterface, cfg *Config) {
-	if bucketName != "" {
-		cleanSingleBucket(client, bucketName, cfg)
-		return
+	if actual == nil {
+		return false, nil
 	}
 
-	buckets, err := client.ListBuckets(context.Background())
+	expected, err := buildLifecycleRule(pr)
 	if err != nil {
-		log.Fatalf("Error listing buckets: %v", err)
+		return false, err
 	}
+	normalizeRuleFilterTags(&expected)
+	normalized := *actual
+	normalizeRuleFilterTags(&normalized)
+	return reflect.DeepEqual(expected, normalized), nil
+}
 
-	for _, bucket := range buckets {
-		cleanSingleBucket(client, bucket.Name, cfg)
+// normalizeRuleFilterTags сортирует rule.RuleFilter.And.Tags по ключу, чтобы
+// сравнение через reflect.DeepEqual не зависело от порядка, в котором MinIO
+// вернул теги, или от недетерминированного порядка range по map при сборке
+// ожидаемого правила.
+func normalizeRuleFilterTags(rule *lifecycle.Rule) {
+	sort.Slice(rule.RuleFilter.And.Tags, func(i, j int) bool {
+		return rule.RuleFilter.And.Tags[i].Key < rule.RuleFilter.And.Tags[j].Key
+	})
+}
+
+func cleanVersions(clients map[string]MinioClientInterface, cfg *Config, reporter *Reporter) error {
+	targetsByName := make(map[string]TargetConfig)
+	for _, t := range resolveTargets(cfg) {
+		targetsByName[t.Name] = t
 	}
+
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := bucketsForTarget(context.Background(), client, targetsByName[name].BucketGlob)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			cleanSingleBucket(name, client, bucket, cfg, reporter)
+		}
+		return nil
+	})
 }
 
-func cleanSingleBucket(client MinioClientInterface, bucket string, cfg *Config) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute) // Таймаут 5 минут
+func cleanSingleBucket(target string, client MinioClientInterface, bucket string, cfg *Config, reporter *Reporter) {
+	ctx, cancel := context.WithTimeout(rootContext(), 5*time.Minute) // Таймаут 5 минут
 	defer cancel()
 
-	log.Printf("Bucket %s: ⌛️ Cleaning versions starting", bucket)
+	start := time.Now()
+	defer func() {
+		runDurationSeconds.WithLabelValues("clean", bucket).Observe(time.Since(start).Seconds())
+	}()
+
+	logger.Info("cleaning versions starting", "target", target, "bucket", bucket)
 	exists, err := client.BucketExists(ctx, bucket)
 	if err != nil {
-		log.Printf("Error checking bucket %s existence: %v", bucket, err)
+		logger.Warn("error checking bucket existence", "target", target, "bucket", bucket, "error", err)
 		return
 	}
 	if !exists {
-		log.Printf("Bucket %s does not exist", bucket)
+		logger.Warn("bucket does not exist", "target", target, "bucket", bucket)
 		return
 	}
 
@@ -380,6 +660,9 @@ func cleanSingleBucket(client MinioClientInterface, bucket string, cfg *Config)
 
 		for obj := range objectsCh {
 			atomic.AddInt64(&totalCount, 1)
+			if dryRun {
+				reporter.RecordObject(bucket, obj)
+			}
 			if !obj.IsLatest {
 
 				select {
@@ -390,7 +673,7 @@ func cleanSingleBucket(client MinioClientInterface, bucket string, cfg *Config)
 				}
 
 				if processedCount >= maxObjectsPerRun {
-					log.Printf("Bucket %s: Reached limit of %d objects for this run", bucket, maxObjectsPerRun)
+					logger.Info("reached per-run object limit", "target", target, "bucket", bucket, "limit", maxObjectsPerRun)
 					return
 					// Не прерываем, чтобы продолжить подсчет общего количества
 				}
@@ -414,13 +697,23 @@ func cleanSingleBucket(client MinioClientInterface, bucket string, cfg *Config)
 
 	// Горутина для сбора ошибок
 	hasErrors := false
+	notDeletedCount := 0 // объекты, отправленные на удаление, но не удаленные (ошибка или object-lock skip)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		for e := range errorCh {
-			log.Printf("Failed to remove %s (version %s): %v",
-				e.ObjectName, e.VersionID, e.Err)
+			if isObjectLockProtectedError(e.Err) {
+				logger.Info("skipping object version under object-lock retention", "target", target, "bucket", bucket,
+					"object", e.ObjectName, "versionId", e.VersionID)
+				objectsSkippedTotal.WithLabelValues(bucket).Inc()
+				notDeletedCount++
+				continue
+			}
+			logger.Error("failed to remove object version", "target", target, "bucket", bucket,
+				"object", e.ObjectName, "versionId", e.VersionID, "error", e.Err)
+			deleteErrorsTotal.WithLabelValues(bucket).Inc()
 			hasErrors = true
+			notDeletedCount++
 		}
 	}()
 
@@ -429,17 +722,20 @@ func cleanSingleBucket(client MinioClientInterface, bucket string, cfg *Config)
 
 	// Логирование результатов
 	total := atomic.LoadInt64(&totalCount)
+	objectsScannedTotal.WithLabelValues(bucket).Add(float64(total))
 	if dryRun {
-		log.Printf("Bucket %s: Planning to delete %d non-current versions (would process %d now)",
-			bucket, processedCount, total)
-		log.Printf("Bucket %s: ✅ Dry run completed", bucket)
+		logger.Info("planning to delete noncurrent versions", "target", target, "bucket", bucket,
+			"toDelete", processedCount, "scanned", total)
+		logger.Info("dry run completed", "target", target, "bucket", bucket)
 	} else {
+		deletedCount := processedCount - notDeletedCount
+		objectsDeletedTotal.WithLabelValues(bucket).Add(float64(deletedCount))
 		if hasErrors {
-			log.Printf("Bucket %s: ❌ Clean completed with errors (processed %d/%d)",
-				bucket, total, processedCount)
+			logger.Error("clean completed with errors", "target", target, "bucket", bucket,
+				"processed", processedCount, "deleted", deletedCount, "scanned", total)
 		} else {
-			log.Printf("Bucket %s: ✅ Successfully deleted %d versions (%d remaining)",
-				bucket, processedCount, total-int64(processedCount))
+			logger.Info("clean completed", "target", target, "bucket", bucket,
+				"deleted", deletedCount, "remaining", total-int64(processedCount))
 		}
 	}
 }
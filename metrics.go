@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	objectsScannedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "minio_cleaner_objects_scanned_total",
+		Help: "Total number of object versions observed while scanning a bucket.",
+	}, []string{"bucket"})
+
+	objectsDeletedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "minio_cleaner_objects_deleted_total",
+		Help: "Total number of noncurrent object versions deleted.",
+	}, []string{"bucket"})
+
+	deleteErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "minio_cleaner_delete_errors_total",
+		Help: "Total number of errors encountered while deleting object versions.",
+	}, []string{"bucket"})
+
+	objectsSkippedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "minio_cleaner_objects_skipped_total",
+		Help: "Total number of object versions skipped because they are under object-lock retention or legal hold.",
+	}, []string{"bucket"})
+
+	runDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "minio_cleaner_run_duration_seconds",
+		Help: "Duration of a command run against a single bucket.",
+	}, []string{"command", "bucket"})
+
+	lifecyclePolicyCorrect = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "minio_cleaner_lifecycle_policy_correct",
+		Help: "1 if the bucket's lifecycle policy matches the configured policy, 0 otherwise.",
+	}, []string{"bucket"})
+)
+
+// startMetricsServer запускает HTTP-сервер с Prometheus-метриками на
+// /metrics и liveness-проверкой на /healthz. Возвращает *http.Server,
+// который вызывающий код должен штатно остановить через Shutdown.
+func startMetricsServer(addr string, clients map[string]MinioClientInterface, canaryBucket string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if err := checkConnectivity(r.Context(), clients, canaryBucket); err != nil {
+			http.Error(w, fmt.Sprintf("unhealthy: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics server stopped unexpectedly", "addr", addr, "error", err)
+		}
+	}()
+	return srv
+}
+
+// checkConnectivity проверяет, что каждая цель из clients доступна. Если
+// canaryBucket задан, проверка делается через BucketExists на этом бакете,
+// иначе используется ListBuckets как менее специфичный признак доступности.
+func checkConnectivity(ctx context.Context, clients map[string]MinioClientInterface, canaryBucket string) error {
+	for name, client := range clients {
+		if canaryBucket != "" {
+			if _, err := client.BucketExists(ctx, canaryBucket); err != nil {
+				return fmt.Errorf("target %s: %w", name, err)
+			}
+			continue
+		}
+		if _, err := client.ListBuckets(ctx); err != nil {
+			return fmt.Errorf("target %s: %w", name, err)
+		}
+	}
+	return nil
+}
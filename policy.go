@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/spf13/viper"
+)
+
+// defaultPolicyName используется, когда для бакета не найдено явного
+// сопоставления в cleaner.bucketPolicies и cleaner.defaultPolicy не задан.
+const defaultPolicyName = "auto-clean-versions"
+
+// PolicyFilterConfig описывает фильтр правила: по префиксу и/или набору
+// тегов (совпадает с Filter.And из pkg/lifecycle, когда задано и то, и
+// другое).
+type PolicyFilterConfig struct {
+	Prefix string            `mapstructure:"prefix"`
+	Tags   map[string]string `mapstructure:"tags"`
+}
+
+// PolicyRuleConfig — декларативное описание одного правила lifecycle,
+// задаваемое пользователем в cleaner.policies или через --policy-file.
+// Поля зеркалят lifecycle.Rule из pkg/lifecycle.
+type PolicyRuleConfig struct {
+	ID     string             `mapstructure:"id"`
+	Status string             `mapstructure:"status"`
+	Filter PolicyFilterConfig `mapstructure:"filter"`
+
+	ExpirationDays     int    `mapstructure:"expirationDays"`
+	ExpirationDate     string `mapstructure:"expirationDate"` // формат "2006-01-02"
+	ExpireDeleteMarker bool   `mapstructure:"expireDeleteMarker"`
+
+	NoncurrentDays          int `mapstructure:"noncurrentDays"`
+	NewerNoncurrentVersions int `mapstructure:"newerNoncurrentVersions"`
+
+	AbortIncompleteMultipartUploadDays int `mapstructure:"abortIncompleteMultipartUploadDays"`
+
+	TransitionDays         int    `mapstructure:"transitionDays"`
+	TransitionDate         string `mapstructure:"transitionDate"`
+	TransitionStorageClass string `mapstructure:"transitionStorageClass"`
+}
+
+// BucketPolicyOverride сопоставляет бакеты, чье имя совпадает с BucketGlob,
+// с именем политики из cleaner.policies. Проверяется по порядку, побеждает
+// первое совпадение.
+type BucketPolicyOverride struct {
+	BucketGlob string `mapstructure:"bucketGlob"`
+	Policy     string `mapstructure:"policy"`
+}
+
+// policyFile описывает корневой документ, загружаемый через --policy-file.
+// Имеет ту же форму, что и секция cleaner в основном конфиге, чтобы файлы
+// политик можно было переиспользовать между конфигурациями.
+type policyFile struct {
+	Policies map[string][]PolicyRuleConfig `mapstructure:"policies"`
+}
+
+// loadPolicyFile читает декларативные политики lifecycle из отдельного
+// YAML-файла (формат XML из pkg/lifecycle не переиспользуется напрямую,
+// т.к. у нас более простая декларативная схема, конвертируемая в
+// lifecycle.Rule функцией buildLifecycleRule).
+func loadPolicyFile(path string) (map[string][]PolicyRuleConfig, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading policy file: %w", err)
+	}
+
+	var pf policyFile
+	if err := v.Unmarshal(&pf); err != nil {
+		return nil, fmt.Errorf("error unmarshaling policy file: %w", err)
+	}
+	return pf.Policies, nil
+}
+
+// mergePolicies добавляет политики из файла в cfg.Cleaner.Policies,
+// политики из файла имеют приоритет при совпадении имен.
+func mergePolicies(cfg *Config, loaded map[string][]PolicyRuleConfig) {
+	if len(loaded) == 0 {
+		return
+	}
+	if cfg.Cleaner.Policies == nil {
+		cfg.Cleaner.Policies = make(map[string][]PolicyRuleConfig)
+	}
+	for name, rules := range loaded {
+		cfg.Cleaner.Policies[name] = rules
+	}
+}
+
+// defaultPolicyRules возвращает встроенную политику, которая раньше была
+// захардкожена в createDefaultLifecycle — используется, если в конфиге нет
+// ни одной декларативной политики.
+func defaultPolicyRules() []PolicyRuleConfig {
+	return []PolicyRuleConfig{
+		{
+			ID:                      defaultPolicyName,
+			Status:                  "Enabled",
+			NoncurrentDays:          1,
+			NewerNoncurrentVersions: 1,
+			ExpireDeleteMarker:      true,
+		},
+	}
+}
+
+// resolvePolicyRules определяет набор правил lifecycle, которые должны
+// действовать для данного бакета: сначала проверяются bucketPolicies по
+// порядку (первое совпадение glob побеждает), затем cleaner.defaultPolicy.
+// Если ни то ни другое не задано, используется политика с именем
+// defaultPolicyName из cleaner.policies, а при ее отсутствии -
+// defaultPolicyRules.
+func resolvePolicyRules(cfg *Config, bucket string) ([]PolicyRuleConfig, error) {
+	policyName := cfg.Cleaner.DefaultPolicy
+
+	for _, override := range cfg.Cleaner.BucketPolicies {
+		matched, err := filepath.Match(override.BucketGlob, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket glob %q: %w", override.BucketGlob, err)
+		}
+		if matched {
+			policyName = override.Policy
+			break
+		}
+	}
+
+	if policyName == "" {
+		if rules, ok := cfg.Cleaner.Policies[defaultPolicyName]; ok {
+			return rules, nil
+		}
+		return defaultPolicyRules(), nil
+	}
+
+	rules, ok := cfg.Cleaner.Policies[policyName]
+	if !ok {
+		return nil, fmt.Errorf("policy %q referenced for bucket %q is not defined", policyName, bucket)
+	}
+	return rules, nil
+}
+
+// buildLifecycleRule конвертирует декларативное описание правила в
+// lifecycle.Rule, пригодный для SetBucketLifecycle.
+func buildLifecycleRule(pr PolicyRuleConfig) (lifecycle.Rule, error) {
+	rule := lifecycle.Rule{
+		ID:     pr.ID,
+		Status: pr.Status,
+	}
+
+	if pr.NoncurrentDays > 0 || pr.NewerNoncurrentVersions > 0 {
+		rule.NoncurrentVersionExpiration = lifecycle.NoncurrentVersionExpiration{
+			NoncurrentDays:          lifecycle.ExpirationDays(pr.NoncurrentDays),
+			NewerNoncurrentVersions: pr.NewerNoncurrentVersions,
+		}
+	}
+
+	if pr.ExpirationDays > 0 {
+		rule.Expiration.Days = lifecycle.ExpirationDays(pr.ExpirationDays)
+	}
+	if pr.ExpirationDate != "" {
+		d, err := time.Parse("2006-01-02", pr.ExpirationDate)
+		if err != nil {
+			return rule, fmt.Errorf("rule %s: invalid expirationDate: %w", pr.ID, err)
+		}
+		rule.Expiration.Date = lifecycle.ExpirationDate{Time: d}
+	}
+	rule.Expiration.DeleteMarker = lifecycle.ExpireDeleteMarker(lifecycle.ExpirationBoolean(pr.ExpireDeleteMarker))
+
+	if pr.AbortIncompleteMultipartUploadDays > 0 {
+		rule.AbortIncompleteMultipartUpload = lifecycle.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: lifecycle.ExpirationDays(pr.AbortIncompleteMultipartUploadDays),
+		}
+	}
+
+	if pr.TransitionStorageClass != "" {
+		rule.Transition.StorageClass = pr.TransitionStorageClass
+		if pr.TransitionDays > 0 {
+			rule.Transition.Days = lifecycle.ExpirationDays(pr.TransitionDays)
+		}
+		if pr.TransitionDate != "" {
+			d, err := time.Parse("2006-01-02", pr.TransitionDate)
+			if err != nil {
+				return rule, fmt.Errorf("rule %s: invalid transitionDate: %w", pr.ID, err)
+			}
+			rule.Transition.Date = lifecycle.ExpirationDate{Time: d}
+		}
+	}
+
+	rule.RuleFilter = buildLifecycleFilter(pr.Filter)
+
+	return rule, nil
+}
+
+// buildLifecycleFilter конвертирует PolicyFilterConfig в lifecycle.Filter,
+// используя Filter.And когда заданы и префикс, и теги (или несколько
+// тегов), как того требует схема pkg/lifecycle.
+func buildLifecycleFilter(f PolicyFilterConfig) lifecycle.Filter {
+	if f.Prefix == "" && len(f.Tags) == 0 {
+		return lifecycle.Filter{}
+	}
+
+	if f.Prefix != "" && len(f.Tags) == 0 {
+		return lifecycle.Filter{Prefix: f.Prefix}
+	}
+
+	if f.Prefix == "" && len(f.Tags) == 1 {
+		for k, v := range f.Tags {
+			return lifecycle.Filter{Tag: lifecycle.Tag{Key: k, Value: v}}
+		}
+	}
+
+	and := lifecycle.And{Prefix: f.Prefix}
+	for k, v := range f.Tags {
+		and.Tags = append(and.Tags, lifecycle.Tag{Key: k, Value: v})
+	}
+	// Ранжирование по map недетерминировано, а это And.Tags сравнивается
+	// через reflect.DeepEqual в hasCorrectRule - без стабильного порядка
+	// правило с 2+ тегами считалось бы "некорректным" на случайных прогонах.
+	sort.Slice(and.Tags, func(i, j int) bool { return and.Tags[i].Key < and.Tags[j].Key })
+	return lifecycle.Filter{And: and}
+}
+
+// buildLifecycleConfig строит полную конфигурацию lifecycle из набора
+// декларативных правил.
+func buildLifecycleConfig(rules []PolicyRuleConfig) (*lifecycle.Configuration, error) {
+	lc := &lifecycle.Configuration{}
+	for _, pr := range rules {
+		rule, err := buildLifecycleRule(pr)
+		if err != nil {
+			return nil, err
+		}
+		lc.Rules = append(lc.Rules, rule)
+	}
+	return lc, nil
+}
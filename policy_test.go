@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePolicyRules(t *testing.T) {
+	cfg := &Config{}
+	cfg.Cleaner.Policies = map[string][]PolicyRuleConfig{
+		"logs-short": {{ID: "expire-logs", Status: "Enabled", ExpirationDays: 7}},
+	}
+	cfg.Cleaner.BucketPolicies = []BucketPolicyOverride{
+		{BucketGlob: "logs-*", Policy: "logs-short"},
+	}
+
+	t.Run("Matches bucket override", func(t *testing.T) {
+		rules, err := resolvePolicyRules(cfg, "logs-prod")
+		assert.NoError(t, err)
+		assert.Equal(t, "expire-logs", rules[0].ID)
+	})
+
+	t.Run("Falls back to built-in default", func(t *testing.T) {
+		rules, err := resolvePolicyRules(cfg, "other-bucket")
+		assert.NoError(t, err)
+		assert.Equal(t, defaultPolicyRules(), rules)
+	})
+
+	t.Run("Unknown policy name is an error", func(t *testing.T) {
+		cfg.Cleaner.DefaultPolicy = "missing"
+		_, err := resolvePolicyRules(cfg, "other-bucket")
+		assert.Error(t, err)
+		cfg.Cleaner.DefaultPolicy = ""
+	})
+}
+
+func TestBuildLifecycleFilter(t *testing.T) {
+	t.Run("Prefix only", func(t *testing.T) {
+		f := buildLifecycleFilter(PolicyFilterConfig{Prefix: "tmp/"})
+		assert.Equal(t, "tmp/", f.Prefix)
+		assert.True(t, f.And.IsEmpty())
+	})
+
+	t.Run("Prefix and tags combine into And", func(t *testing.T) {
+		f := buildLifecycleFilter(PolicyFilterConfig{Prefix: "tmp/", Tags: map[string]string{"env": "ci"}})
+		assert.Equal(t, "tmp/", f.And.Prefix)
+		assert.Len(t, f.And.Tags, 1)
+	})
+
+	t.Run("Multiple tags are sorted deterministically by key", func(t *testing.T) {
+		tags := map[string]string{"env": "ci", "team": "platform", "owner": "ops"}
+		want := []lifecycle.Tag{{Key: "env", Value: "ci"}, {Key: "owner", Value: "ops"}, {Key: "team", Value: "platform"}}
+
+		for i := 0; i < 20; i++ {
+			f := buildLifecycleFilter(PolicyFilterConfig{Prefix: "tmp/", Tags: tags})
+			assert.Equal(t, want, f.And.Tags)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Возрастные категории noncurrent-версий, используемые для разбивки
+// reclaimableBytes в отчете dry-run.
+const (
+	ageBucketUnder1Day  = "<1d"
+	ageBucket1To7Days   = "1-7d"
+	ageBucket7To30Days  = "7-30d"
+	ageBucketOver30Days = ">30d"
+)
+
+var ageBucketOrder = []string{ageBucketUnder1Day, ageBucket1To7Days, ageBucket7To30Days, ageBucketOver30Days}
+
+func ageBucketFor(age time.Duration) string {
+	switch {
+	case age < 24*time.Hour:
+		return ageBucketUnder1Day
+	case age < 7*24*time.Hour:
+		return ageBucket1To7Days
+	case age < 30*24*time.Hour:
+		return ageBucket7To30Days
+	default:
+		return ageBucketOver30Days
+	}
+}
+
+// ReportedObject описывает один noncurrent-объект в разделе top-N
+// крупнейших объектов отчета.
+type ReportedObject struct {
+	Key        string        `json:"key"`
+	VersionID  string        `json:"versionId"`
+	Size       int64         `json:"size"`
+	Age        time.Duration `json:"-"`
+	AgeSeconds int64         `json:"ageSeconds"`
+}
+
+// BucketReport накапливает статистику одного бакета за время прогона
+// check/apply/clean: состояние политики (check/apply) и/или размеры
+// noncurrent-версий, которые clean в режиме --dry-run нашел бы для удаления.
+type BucketReport struct {
+	Bucket             string           `json:"bucket"`
+	PolicyCorrect      *bool            `json:"policyCorrect,omitempty"`
+	ScannedVersions    int64            `json:"scannedVersions,omitempty"`
+	NoncurrentVersions int64            `json:"noncurrentVersions,omitempty"`
+	DeleteMarkers      int64            `json:"deleteMarkers,omitempty"`
+	ReclaimableBytes   int64            `json:"reclaimableBytes,omitempty"`
+	SizeByAge          map[string]int64 `json:"sizeByAge,omitempty"`
+	TopLargest         []ReportedObject `json:"topLargestNoncurrent,omitempty"`
+}
+
+// Reporter собирает per-bucket статистику прогонов check/apply/clean и
+// рендерит ее в JSON, CSV или человекочитаемую таблицу (--report-format),
+// чтобы операторы могли обосновать включение политики до запуска
+// деструктивного clean, и чтобы CI мог архивировать артефакт прогона.
+type Reporter struct {
+	mu      sync.Mutex
+	topN    int
+	buckets map[string]*BucketReport
+	order   []string
+}
+
+// NewReporter создает Reporter, хранящий не более topN крупнейших
+// noncurrent-объектов на бакет.
+func NewReporter(topN int) *Reporter {
+	return &Reporter{topN: topN, buckets: make(map[string]*BucketReport)}
+}
+
+func (r *Reporter) bucketReport(bucket string) *BucketReport {
+	br, ok := r.buckets[bucket]
+	if !ok {
+		br = &BucketReport{Bucket: bucket, SizeByAge: make(map[string]int64)}
+		r.buckets[bucket] = br
+		r.order = append(r.order, bucket)
+	}
+	return br
+}
+
+// RecordPolicyStatus фиксирует результат проверки/применения lifecycle
+// политики для бакета (используется check и apply).
+func (r *Reporter) RecordPolicyStatus(bucket string, correct bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bucketReport(bucket).PolicyCorrect = &correct
+}
+
+// RecordObject регистрирует одну версию объекта, увиденную clean при
+// сканировании бакета в режиме --dry-run.
+func (r *Reporter) RecordObject(bucket string, obj minio.ObjectInfo) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	br := r.bucketReport(bucket)
+	br.ScannedVersions++
+	if obj.IsLatest {
+		return
+	}
+
+	br.NoncurrentVersions++
+	if obj.IsDeleteMarker {
+		br.DeleteMarkers++
+	}
+	br.ReclaimableBytes += obj.Size
+
+	age := time.Since(obj.LastModified)
+	br.SizeByAge[ageBucketFor(age)] += obj.Size
+
+	br.TopLargest = append(br.TopLargest, ReportedObject{
+		Key: obj.Key, VersionID: obj.VersionID, Size: obj.Size, Age: age, AgeSeconds: int64(age.Seconds()),
+	})
+	sort.Slice(br.TopLargest, func(i, j int) bool { return br.TopLargest[i].Size > br.TopLargest[j].Size })
+	if len(br.TopLargest) > r.topN {
+		br.TopLargest = br.TopLargest[:r.topN]
+	}
+}
+
+// Render пишет накопленный отчет в w в запрошенном формате: "json", "csv"
+// или "table" (значение по умолчанию для нераспознанного формата).
+func (r *Reporter) Render(w io.Writer, format string) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reports := make([]*BucketReport, 0, len(r.order))
+	for _, name := range r.order {
+		reports = append(reports, r.buckets[name])
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	case "csv":
+		return renderReportCSV(w, reports)
+	default:
+		return renderReportTable(w, reports)
+	}
+}
+
+func renderReportCSV(w io.Writer, reports []*BucketReport) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{"bucket", "policyCorrect", "scannedVersions", "noncurrentVersions", "deleteMarkers", "reclaimableBytes"}
+	for _, age := range ageBucketOrder {
+		header = append(header, "size"+age)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, br := range reports {
+		policyCorrect := ""
+		if br.PolicyCorrect != nil {
+			policyCorrect = fmt.Sprintf("%t", *br.PolicyCorrect)
+		}
+		row := []string{
+			br.Bucket,
+			policyCorrect,
+			fmt.Sprintf("%d", br.ScannedVersions),
+			fmt.Sprintf("%d", br.NoncurrentVersions),
+			fmt.Sprintf("%d", br.DeleteMarkers),
+			fmt.Sprintf("%d", br.ReclaimableBytes),
+		}
+		for _, age := range ageBucketOrder {
+			row = append(row, fmt.Sprintf("%d", br.SizeByAge[age]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	return cw.Error()
+}
+
+func renderReportTable(w io.Writer, reports []*BucketReport) error {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "BUCKET\tPOLICY OK\tSCANNED\tNONCURRENT\tDELETE MARKERS\tRECLAIMABLE")
+	var totalReclaimable int64
+	for _, br := range reports {
+		policyCorrect := "-"
+		if br.PolicyCorrect != nil {
+			policyCorrect = fmt.Sprintf("%t", *br.PolicyCorrect)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\n",
+			br.Bucket, policyCorrect, br.ScannedVersions, br.NoncurrentVersions, br.DeleteMarkers, br.ReclaimableBytes)
+		totalReclaimable += br.ReclaimableBytes
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t\t\t\t%d\n", totalReclaimable)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, br := range reports {
+		if len(br.TopLargest) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\nTop noncurrent objects in %s:\n", br.Bucket)
+		objTw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+		fmt.Fprintln(objTw, "KEY\tVERSION\tSIZE\tAGE")
+		for _, obj := range br.TopLargest {
+			fmt.Fprintf(objTw, "%s\t%s\t%d\t%s\n", obj.Key, obj.VersionID, obj.Size, obj.Age.Round(time.Hour))
+		}
+		if err := objTw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgeBucketFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		age      time.Duration
+		expected string
+	}{
+		{"Just now", time.Minute, ageBucketUnder1Day},
+		{"Three days", 3 * 24 * time.Hour, ageBucket1To7Days},
+		{"Twenty days", 20 * 24 * time.Hour, ageBucket7To30Days},
+		{"Three months", 90 * 24 * time.Hour, ageBucketOver30Days},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ageBucketFor(tt.age))
+		})
+	}
+}
+
+func TestReporterRecordObject(t *testing.T) {
+	r := NewReporter(2)
+
+	r.RecordObject("bucket1", minio.ObjectInfo{Key: "latest", IsLatest: true, Size: 100})
+	r.RecordObject("bucket1", minio.ObjectInfo{Key: "old-small", Size: 10, LastModified: time.Now()})
+	r.RecordObject("bucket1", minio.ObjectInfo{Key: "old-big", Size: 1000, LastModified: time.Now()})
+	r.RecordObject("bucket1", minio.ObjectInfo{Key: "old-medium", Size: 100, LastModified: time.Now()})
+	r.RecordObject("bucket1", minio.ObjectInfo{Key: "marker", IsDeleteMarker: true, Size: 0, LastModified: time.Now()})
+
+	br := r.bucketReport("bucket1")
+	assert.EqualValues(t, 5, br.ScannedVersions)
+	assert.EqualValues(t, 4, br.NoncurrentVersions)
+	assert.EqualValues(t, 1, br.DeleteMarkers)
+	assert.EqualValues(t, 1110, br.ReclaimableBytes)
+	assert.EqualValues(t, 1110, br.SizeByAge[ageBucketUnder1Day])
+
+	// top-N truncated to 2, sorted by size descending
+	assert.Len(t, br.TopLargest, 2)
+	assert.Equal(t, "old-big", br.TopLargest[0].Key)
+	assert.Equal(t, "old-medium", br.TopLargest[1].Key)
+}
+
+func TestReporterRecordPolicyStatus(t *testing.T) {
+	r := NewReporter(5)
+	r.RecordPolicyStatus("bucket1", true)
+
+	br := r.bucketReport("bucket1")
+	assert.NotNil(t, br.PolicyCorrect)
+	assert.True(t, *br.PolicyCorrect)
+}
+
+func TestReporterRenderJSON(t *testing.T) {
+	r := NewReporter(5)
+	r.RecordPolicyStatus("bucket1", false)
+	r.RecordObject("bucket1", minio.ObjectInfo{Key: "old", Size: 42, LastModified: time.Now()})
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Render(&buf, "json"))
+
+	var reports []BucketReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &reports))
+	assert.Len(t, reports, 1)
+	assert.Equal(t, "bucket1", reports[0].Bucket)
+	assert.EqualValues(t, 42, reports[0].ReclaimableBytes)
+}
+
+func TestReporterRenderCSV(t *testing.T) {
+	r := NewReporter(5)
+	r.RecordObject("bucket1", minio.ObjectInfo{Key: "old", Size: 42, LastModified: time.Now()})
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Render(&buf, "csv"))
+	assert.Contains(t, buf.String(), "bucket,policyCorrect")
+	assert.Contains(t, buf.String(), "bucket1")
+}
+
+func TestReporterNilIsSafe(t *testing.T) {
+	var r *Reporter
+	r.RecordPolicyStatus("bucket1", true)
+	r.RecordObject("bucket1", minio.ObjectInfo{})
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Render(&buf, "json"))
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Допустимые значения RetentionConfig.Scope. При любом scope "retention apply"
+// устанавливает конфигурацию object-lock бакета по умолчанию (для новых версий
+// объектов); при "prefix" он дополнительно выставляет retention (и legal hold,
+// если он включен) на текущие версии объектов с заданным префиксом.
+const (
+	RetentionScopeBucket = "bucket"
+	RetentionScopePrefix = "prefix"
+)
+
+// RetentionConfig описывает режим object-lock retention, которым должны
+// управлять команды "retention apply"/"retention check".
+type RetentionConfig struct {
+	Mode         string `mapstructure:"mode"` // GOVERNANCE или COMPLIANCE
+	ValidityDays int    `mapstructure:"validityDays"`
+	Scope        string `mapstructure:"scope"` // "bucket" (по умолчанию) или "prefix"
+	Prefix       string `mapstructure:"prefix"`
+	LegalHold    bool   `mapstructure:"legalHold"`
+}
+
+// runRetentionCommand обрабатывает подкоманды "retention apply|check".
+func runRetentionCommand(clients map[string]MinioClientInterface, args []string, cfg *Config) error {
+	if len(args) == 0 {
+		return fmt.Errorf("retention subcommand required: apply or check")
+	}
+
+	switch args[0] {
+	case "apply":
+		return retentionApply(clients, cfg)
+	case "check":
+		return retentionCheck(clients, cfg)
+	default:
+		return fmt.Errorf("unknown retention subcommand: %s", args[0])
+	}
+}
+
+// resolveRetentionMode конвертирует RetentionConfig в типы minio-go,
+// используемые SetBucketObjectLockConfig/PutObjectRetention.
+func resolveRetentionMode(rc RetentionConfig) (*minio.RetentionMode, *uint, *minio.ValidityUnit, error) {
+	if rc.Mode == "" {
+		return nil, nil, nil, nil
+	}
+
+	mode := minio.RetentionMode(strings.ToUpper(rc.Mode))
+	if !mode.IsValid() {
+		return nil, nil, nil, fmt.Errorf("invalid retention mode %q", rc.Mode)
+	}
+	if rc.ValidityDays <= 0 {
+		return nil, nil, nil, fmt.Errorf("retention validityDays must be positive for mode %q", rc.Mode)
+	}
+
+	validity := uint(rc.ValidityDays)
+	unit := minio.Days
+	return &mode, &validity, &unit, nil
+}
+
+func retentionApply(clients map[string]MinioClientInterface, cfg *Config) error {
+	mode, validity, unit, err := resolveRetentionMode(cfg.Cleaner.Retention)
+	if err != nil {
+		return err
+	}
+
+	targetsByName := make(map[string]TargetConfig)
+	for _, t := range resolveTargets(cfg) {
+		targetsByName[t.Name] = t
+	}
+
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := bucketsForTarget(context.Background(), client, targetsByName[name].BucketGlob)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			if err := applyBucketRetention(name, client, cfg, bucket, mode, validity, unit); err != nil {
+				logger.Error("error applying retention", "target", name, "bucket", bucket, "error", err)
+			}
+		}
+		return nil
+	})
+}
+
+func applyBucketRetention(target string, client MinioClientInterface, cfg *Config, bucket string, mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit) error {
+	ctx := context.Background()
+	rc := cfg.Cleaner.Retention
+
+	if err := client.SetBucketObjectLockConfig(ctx, bucket, mode, validity, unit); err != nil {
+		return fmt.Errorf("error setting bucket object-lock config: %w", err)
+	}
+	logger.Info("bucket object-lock config applied", "target", target, "bucket", bucket, "mode", rc.Mode, "validityDays", rc.ValidityDays)
+
+	if rc.Scope != RetentionScopePrefix {
+		return nil
+	}
+	if mode == nil {
+		return nil
+	}
+
+	retainUntil := time.Now().AddDate(0, 0, rc.ValidityDays)
+	objectsCh := client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: rc.Prefix, Recursive: true})
+	for obj := range objectsCh {
+		if obj.Err != nil {
+			logger.Warn("error listing object for retention", "target", target, "bucket", bucket, "error", obj.Err)
+			continue
+		}
+
+		err := client.PutObjectRetention(ctx, bucket, obj.Key, minio.PutObjectRetentionOptions{
+			Mode:            mode,
+			RetainUntilDate: &retainUntil,
+			VersionID:       obj.VersionID,
+		})
+		if err != nil {
+			logger.Error("error setting object retention", "target", target, "bucket", bucket, "object", obj.Key, "error", err)
+			continue
+		}
+
+		if rc.LegalHold {
+			status := minio.LegalHoldEnabled
+			if err := client.PutObjectLegalHold(ctx, bucket, obj.Key, minio.PutObjectLegalHoldOptions{
+				VersionID: obj.VersionID,
+				Status:    &status,
+			}); err != nil {
+				logger.Error("error setting legal hold", "target", target, "bucket", bucket, "object", obj.Key, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+func retentionCheck(clients map[string]MinioClientInterface, cfg *Config) error {
+	targetsByName := make(map[string]TargetConfig)
+	for _, t := range resolveTargets(cfg) {
+		targetsByName[t.Name] = t
+	}
+
+	return forEachTarget(clients, func(name string, client MinioClientInterface) error {
+		buckets, err := bucketsForTarget(context.Background(), client, targetsByName[name].BucketGlob)
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			_, mode, validity, unit, err := client.GetObjectLockConfig(context.Background(), bucket)
+			if err != nil {
+				logger.Warn("error getting object-lock config", "target", name, "bucket", bucket, "error", err)
+				continue
+			}
+
+			if objectLockMatches(cfg.Cleaner.Retention, mode, validity, unit) {
+				logger.Info("object-lock config matches expected retention", "target", name, "bucket", bucket)
+			} else {
+				logger.Warn("object-lock config differs from expected retention", "target", name, "bucket", bucket)
+			}
+		}
+		return nil
+	})
+}
+
+func objectLockMatches(rc RetentionConfig, mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit) bool {
+	if rc.Mode == "" {
+		return mode == nil
+	}
+	if mode == nil || validity == nil || unit == nil {
+		return false
+	}
+	return strings.EqualFold(string(*mode), rc.Mode) && int(*validity) == rc.ValidityDays && *unit == minio.Days
+}
+
+// preflightObjectLock отказывает в применении правил, содержащих
+// Expiration.DeleteMarker, если на бакете включен object-lock: S3 запрещает
+// удаление delete-маркеров автоматической политикой lifecycle, пока версии
+// объектов находятся под WORM-защитой, а подобная попытка обнаруживается
+// только в рантайме SetBucketLifecycle без внятного сообщения об ошибке.
+func preflightObjectLock(ctx context.Context, client MinioClientInterface, bucketName string, rules []PolicyRuleConfig) error {
+	hasDeleteMarkerRule := false
+	for _, pr := range rules {
+		if pr.ExpireDeleteMarker {
+			hasDeleteMarkerRule = true
+			break
+		}
+	}
+	if !hasDeleteMarkerRule {
+		return nil
+	}
+
+	objectLock, _, _, _, err := client.GetObjectLockConfig(ctx, bucketName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "ObjectLockConfigurationNotFoundError" {
+			return nil
+		}
+		return fmt.Errorf("error checking object-lock config: %w", err)
+	}
+	if strings.EqualFold(objectLock, "Enabled") {
+		return fmt.Errorf("policy has an Expiration.DeleteMarker rule but object-lock is enabled on this bucket")
+	}
+	return nil
+}
+
+// isObjectLockProtectedError сообщает, что ошибка удаления объекта вызвана
+// активной object-lock retention или legal hold (WORM-защита), а не иной
+// проблемой. Такие объекты должны пропускаться, а не считаться ошибками.
+func isObjectLockProtectedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "ObjectLocked" || resp.Code == "InvalidRequest" && strings.Contains(strings.ToUpper(resp.Message), "WORM") {
+		return true
+	}
+	return strings.Contains(strings.ToUpper(err.Error()), "WORM")
+}
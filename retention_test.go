@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRetentionMode(t *testing.T) {
+	t.Run("Empty config disables object-lock", func(t *testing.T) {
+		mode, validity, unit, err := resolveRetentionMode(RetentionConfig{})
+		assert.NoError(t, err)
+		assert.Nil(t, mode)
+		assert.Nil(t, validity)
+		assert.Nil(t, unit)
+	})
+
+	t.Run("Valid governance mode", func(t *testing.T) {
+		mode, validity, unit, err := resolveRetentionMode(RetentionConfig{Mode: "governance", ValidityDays: 30})
+		assert.NoError(t, err)
+		assert.Equal(t, minio.Governance, *mode)
+		assert.Equal(t, uint(30), *validity)
+		assert.Equal(t, minio.Days, *unit)
+	})
+
+	t.Run("Invalid mode is an error", func(t *testing.T) {
+		_, _, _, err := resolveRetentionMode(RetentionConfig{Mode: "bogus", ValidityDays: 30})
+		assert.Error(t, err)
+	})
+
+	t.Run("Missing validity is an error", func(t *testing.T) {
+		_, _, _, err := resolveRetentionMode(RetentionConfig{Mode: "COMPLIANCE"})
+		assert.Error(t, err)
+	})
+}
+
+func TestObjectLockMatches(t *testing.T) {
+	governance := minio.Governance
+	validity := uint(14)
+	days := minio.Days
+
+	t.Run("Matches configured mode and validity", func(t *testing.T) {
+		rc := RetentionConfig{Mode: "GOVERNANCE", ValidityDays: 14}
+		assert.True(t, objectLockMatches(rc, &governance, &validity, &days))
+	})
+
+	t.Run("Mismatched validity", func(t *testing.T) {
+		rc := RetentionConfig{Mode: "GOVERNANCE", ValidityDays: 7}
+		assert.False(t, objectLockMatches(rc, &governance, &validity, &days))
+	})
+
+	t.Run("No config expects no bucket object-lock", func(t *testing.T) {
+		assert.True(t, objectLockMatches(RetentionConfig{}, nil, nil, nil))
+		assert.False(t, objectLockMatches(RetentionConfig{}, &governance, &validity, &days))
+	})
+}
+
+func TestIsObjectLockProtectedError(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		assert.False(t, isObjectLockProtectedError(nil))
+	})
+
+	t.Run("WORM-protected error response", func(t *testing.T) {
+		err := minio.ErrorResponse{Code: "InvalidRequest", Message: "Object is WORM protected and cannot be overwritten"}
+		assert.True(t, isObjectLockProtectedError(err))
+	})
+
+	t.Run("unrelated error", func(t *testing.T) {
+		assert.False(t, isObjectLockProtectedError(errors.New("network timeout")))
+	})
+}
+
+func TestPreflightObjectLock(t *testing.T) {
+	t.Run("No delete-marker rule skips the check", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		err := preflightObjectLock(context.Background(), mockClient, "bucket", []PolicyRuleConfig{{ID: "a"}})
+		assert.NoError(t, err)
+		mockClient.AssertNotCalled(t, "GetObjectLockConfig")
+	})
+
+	t.Run("Refuses delete-marker rule when object-lock is enabled", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		mockClient.On("GetObjectLockConfig", context.Background(), "bucket").Return(
+			"Enabled", (*minio.RetentionMode)(nil), (*uint)(nil), (*minio.ValidityUnit)(nil), nil,
+		)
+		err := preflightObjectLock(context.Background(), mockClient, "bucket", []PolicyRuleConfig{{ID: "a", ExpireDeleteMarker: true}})
+		assert.Error(t, err)
+	})
+
+	t.Run("Allows delete-marker rule when object-lock is not enabled", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		mockClient.On("GetObjectLockConfig", context.Background(), "bucket").Return(
+			"", (*minio.RetentionMode)(nil), (*uint)(nil), (*minio.ValidityUnit)(nil), nil,
+		)
+		err := preflightObjectLock(context.Background(), mockClient, "bucket", []PolicyRuleConfig{{ID: "a", ExpireDeleteMarker: true}})
+		assert.NoError(t, err)
+	})
+}
+
+func TestRetentionCheckHonorsTargetBucketGlob(t *testing.T) {
+	mockClient := new(MockMinioClient)
+	cfg := &Config{Targets: []TargetConfig{{Name: "default", BucketGlob: "prod-*"}}}
+
+	buckets := []minio.BucketInfo{
+		{Name: "prod-a"},
+		{Name: "staging-a"},
+	}
+	mockClient.On("ListBuckets", context.Background()).Return(buckets, nil)
+	mockClient.On("GetObjectLockConfig", context.Background(), "prod-a").Return(
+		"", (*minio.RetentionMode)(nil), (*uint)(nil), (*minio.ValidityUnit)(nil), nil,
+	)
+
+	err := retentionCheck(map[string]MinioClientInterface{"default": mockClient}, cfg)
+	assert.NoError(t, err)
+	mockClient.AssertNotCalled(t, "GetObjectLockConfig", context.Background(), "staging-a")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"io"
 	"testing"
 
 	"github.com/minio/minio-go/v7"
@@ -35,6 +36,78 @@ func (m *MockMinioClient) BucketExists(ctx context.Context, bucketName string) (
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockMinioClient) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	args := m.Called(ctx, bucketName, opts)
+	return args.Get(0).(<-chan minio.ObjectInfo)
+}
+
+func (m *MockMinioClient) RemoveObjects(ctx context.Context, bucketName string, objectsCh <-chan minio.ObjectInfo, opts minio.RemoveObjectsOptions) <-chan minio.RemoveObjectError {
+	args := m.Called(ctx, bucketName, objectsCh, opts)
+	return args.Get(0).(<-chan minio.RemoveObjectError)
+}
+
+func (m *MockMinioClient) GetBucketPolicy(ctx context.Context, bucketName string) (string, error) {
+	args := m.Called(ctx, bucketName)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMinioClient) SetBucketPolicy(ctx context.Context, bucketName string, policy string) error {
+	args := m.Called(ctx, bucketName, policy)
+	return args.Error(0)
+}
+
+func (m *MockMinioClient) DeleteBucketPolicy(ctx context.Context, bucketName string) error {
+	args := m.Called(ctx, bucketName)
+	return args.Error(0)
+}
+
+func (m *MockMinioClient) SetBucketObjectLockConfig(ctx context.Context, bucketName string, mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit) error {
+	args := m.Called(ctx, bucketName, mode, validity, unit)
+	return args.Error(0)
+}
+
+func (m *MockMinioClient) GetObjectLockConfig(ctx context.Context, bucketName string) (string, *minio.RetentionMode, *uint, *minio.ValidityUnit, error) {
+	args := m.Called(ctx, bucketName)
+	var mode *minio.RetentionMode
+	if v := args.Get(1); v != nil {
+		mode = v.(*minio.RetentionMode)
+	}
+	var validity *uint
+	if v := args.Get(2); v != nil {
+		validity = v.(*uint)
+	}
+	var unit *minio.ValidityUnit
+	if v := args.Get(3); v != nil {
+		unit = v.(*minio.ValidityUnit)
+	}
+	return args.String(0), mode, validity, unit, args.Error(4)
+}
+
+func (m *MockMinioClient) PutObjectRetention(ctx context.Context, bucketName, objectName string, opts minio.PutObjectRetentionOptions) error {
+	args := m.Called(ctx, bucketName, objectName, opts)
+	return args.Error(0)
+}
+
+func (m *MockMinioClient) PutObjectLegalHold(ctx context.Context, bucketName, objectName string, opts minio.PutObjectLegalHoldOptions) error {
+	args := m.Called(ctx, bucketName, objectName, opts)
+	return args.Error(0)
+}
+
+func (m *MockMinioClient) PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	args := m.Called(ctx, bucketName, objectName, reader, objectSize, opts)
+	return args.Get(0).(minio.UploadInfo), args.Error(1)
+}
+
+func (m *MockMinioClient) StatObject(ctx context.Context, bucketName, objectName string, opts minio.StatObjectOptions) (minio.ObjectInfo, error) {
+	args := m.Called(ctx, bucketName, objectName, opts)
+	return args.Get(0).(minio.ObjectInfo), args.Error(1)
+}
+
+func (m *MockMinioClient) RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error {
+	args := m.Called(ctx, bucketName, objectName, opts)
+	return args.Error(0)
+}
+
 func TestListBuckets(t *testing.T) {
 	mockClient := new(MockMinioClient)
 	expectedBuckets := []minio.BucketInfo{
@@ -44,28 +117,22 @@ func TestListBuckets(t *testing.T) {
 
 	mockClient.On("ListBuckets", context.Background()).Return(expectedBuckets, nil)
 
-	listBuckets(mockClient)
+	err := listBuckets(map[string]MinioClientInterface{"default": mockClient})
+	assert.NoError(t, err)
 
 	mockClient.AssertExpectations(t)
 }
 
 func TestCheckLifecycle(t *testing.T) {
+	cfg := &Config{}
+
 	t.Run("With existing policy", func(t *testing.T) {
 		mockClient := new(MockMinioClient)
-		lc := &lifecycle.Configuration{
-			Rules: []lifecycle.Rule{
-				{
-					ID:     "auto-clean-versions",
-					Status: "Enabled",
-					NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
-						NoncurrentDays: 1,
-					},
-				},
-			},
-		}
+		lc, err := buildLifecycleConfig(defaultPolicyRules())
+		assert.NoError(t, err)
 
 		mockClient.On("GetBucketLifecycle", context.Background(), "test-bucket").Return(lc, nil)
-		checkSingleBucket(mockClient, "test-bucket")
+		checkSingleBucket("default", mockClient, cfg, "test-bucket", nil)
 	})
 
 	t.Run("Without policy", func(t *testing.T) {
@@ -78,12 +145,32 @@ func TestCheckLifecycle(t *testing.T) {
 			&lifecycle.Configuration{},
 			errResponse,
 		)
-		checkSingleBucket(mockClient, "test-bucket")
+		checkSingleBucket("default", mockClient, cfg, "test-bucket", nil)
+	})
+
+	t.Run("Honors target bucketGlob", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		globCfg := &Config{Targets: []TargetConfig{{Name: "default", BucketGlob: "prod-*"}}}
+
+		buckets := []minio.BucketInfo{
+			{Name: "prod-a"},
+			{Name: "staging-a"},
+		}
+		mockClient.On("ListBuckets", context.Background()).Return(buckets, nil)
+		mockClient.On("GetBucketLifecycle", context.Background(), "prod-a").Return(
+			&lifecycle.Configuration{},
+			minio.ErrorResponse{Code: "NoSuchLifecycleConfiguration"},
+		)
+
+		err := checkLifecycle(map[string]MinioClientInterface{"default": mockClient}, globCfg, nil)
+		assert.NoError(t, err)
+		mockClient.AssertNotCalled(t, "GetBucketLifecycle", context.Background(), "staging-a")
 	})
 }
 
 func TestApplyLifecycle(t *testing.T) {
 	mockClient := new(MockMinioClient)
+	cfg := &Config{}
 
 	t.Run("Apply to all buckets", func(t *testing.T) {
 		buckets := []minio.BucketInfo{
@@ -93,73 +180,133 @@ func TestApplyLifecycle(t *testing.T) {
 
 		mockClient.On("ListBuckets", context.Background()).Return(buckets, nil)
 		mockClient.On("BucketExists", context.Background(), mock.Anything).Return(true, nil)
+		mockClient.On("GetObjectLockConfig", context.Background(), mock.Anything).Return(
+			"", (*minio.RetentionMode)(nil), (*uint)(nil), (*minio.ValidityUnit)(nil), nil,
+		)
 		mockClient.On("GetBucketLifecycle", context.Background(), mock.Anything).Return(
 			&lifecycle.Configuration{},
 			minio.ErrorResponse{Code: "NoSuchLifecycleConfiguration"},
 		)
 		mockClient.On("SetBucketLifecycle", context.Background(), mock.Anything, mock.Anything).Return(nil)
 
-		applyLifecycle(mockClient)
+		err := applyLifecycle(map[string]MinioClientInterface{"default": mockClient}, cfg, nil)
+		assert.NoError(t, err)
 		mockClient.AssertNumberOfCalls(t, "SetBucketLifecycle", 2)
 	})
+
+	t.Run("Honors target bucketGlob", func(t *testing.T) {
+		mockClient := new(MockMinioClient)
+		cfg := &Config{Targets: []TargetConfig{{Name: "default", BucketGlob: "prod-*"}}}
+
+		buckets := []minio.BucketInfo{
+			{Name: "prod-a"},
+			{Name: "staging-a"},
+		}
+		mockClient.On("ListBuckets", context.Background()).Return(buckets, nil)
+		mockClient.On("BucketExists", context.Background(), "prod-a").Return(true, nil)
+		mockClient.On("GetObjectLockConfig", context.Background(), "prod-a").Return(
+			"", (*minio.RetentionMode)(nil), (*uint)(nil), (*minio.ValidityUnit)(nil), nil,
+		)
+		mockClient.On("GetBucketLifecycle", context.Background(), "prod-a").Return(
+			&lifecycle.Configuration{},
+			minio.ErrorResponse{Code: "NoSuchLifecycleConfiguration"},
+		)
+		mockClient.On("SetBucketLifecycle", context.Background(), "prod-a", mock.Anything).Return(nil)
+
+		err := applyLifecycle(map[string]MinioClientInterface{"default": mockClient}, cfg, nil)
+		assert.NoError(t, err)
+		mockClient.AssertNumberOfCalls(t, "SetBucketLifecycle", 1)
+		mockClient.AssertNotCalled(t, "BucketExists", context.Background(), "staging-a")
+	})
 }
 
 func TestHasCorrectPolicy(t *testing.T) {
+	correctLC, err := buildLifecycleConfig(defaultPolicyRules())
+	assert.NoError(t, err)
+
+	incorrectLC := &lifecycle.Configuration{
+		Rules: []lifecycle.Rule{
+			{
+				ID:     defaultPolicyName,
+				Status: "Enabled",
+				NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
+					NoncurrentDays: 2,
+				},
+			},
+		},
+	}
+
 	tests := []struct {
 		name     string
 		config   *lifecycle.Configuration
 		expected bool
 	}{
-		{
-			name: "Correct policy",
-			config: &lifecycle.Configuration{
-				Rules: []lifecycle.Rule{
-					{
-						ID:     "auto-clean-versions",
-						Status: "Enabled",
-						NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
-							NoncurrentDays: 1,
-						},
-					},
-				},
-			},
-			expected: true,
-		},
-		{
-			name: "Incorrect days",
-			config: &lifecycle.Configuration{
-				Rules: []lifecycle.Rule{
-					{
-						ID:     "auto-clean-versions",
-						Status: "Enabled",
-						NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
-							NoncurrentDays: 2,
-						},
-					},
-				},
-			},
-			expected: false,
-		},
+		{name: "Correct policy", config: correctLC, expected: true},
+		{name: "Incorrect days", config: incorrectLC, expected: false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, hasCorrectPolicy(tt.config))
+			ok, err := hasCorrectPolicy(defaultPolicyRules(), tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
 		})
 	}
 }
 
-func TestUpdateLifecycleConfig(t *testing.T) {
-	existing := &lifecycle.Configuration{
-		Rules: []lifecycle.Rule{
-			{
-				ID:     "old-rule",
-				Status: "Enabled",
-			},
+func TestHasCorrectRuleMultiTagIsStable(t *testing.T) {
+	rule := PolicyRuleConfig{
+		ID:             "expire-tagged",
+		Status:         "Enabled",
+		ExpirationDays: 30,
+		Filter: PolicyFilterConfig{
+			Prefix: "logs/",
+			Tags:   map[string]string{"env": "ci", "team": "platform", "owner": "ops"},
 		},
 	}
 
-	updated := updateLifecycleConfig(existing)
-	assert.Len(t, updated.Rules, 2)
-	assert.Equal(t, "auto-clean-versions", updated.Rules[1].ID)
+	lc, err := buildLifecycleConfig([]PolicyRuleConfig{rule})
+	assert.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		ok, err := hasCorrectRule(rule, lc)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestResolveTargets(t *testing.T) {
+	t.Run("Falls back to legacy minio block", func(t *testing.T) {
+		cfg := &Config{}
+		cfg.Minio.Endpoint = "localhost:9000"
+
+		targets := resolveTargets(cfg)
+		assert.Len(t, targets, 1)
+		assert.Equal(t, "default", targets[0].Name)
+		assert.Equal(t, "localhost:9000", targets[0].Endpoint)
+	})
+
+	t.Run("Uses explicit targets list", func(t *testing.T) {
+		cfg := &Config{
+			Targets: []TargetConfig{
+				{Name: "tenant-a", Endpoint: "a:9000"},
+				{Name: "tenant-b", Endpoint: "b:9000"},
+			},
+		}
+
+		targets := resolveTargets(cfg)
+		assert.Len(t, targets, 2)
+	})
+}
+
+func TestForEachTarget(t *testing.T) {
+	parallelism = 2
+	okClient := new(MockMinioClient)
+	okClient.On("ListBuckets", context.Background()).Return([]minio.BucketInfo{}, nil)
+
+	err := forEachTarget(map[string]MinioClientInterface{"tenant-a": okClient}, func(name string, client MinioClientInterface) error {
+		_, err := client.ListBuckets(context.Background())
+		return err
+	})
+	assert.NoError(t, err)
 }